@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/whiplashvin/alpstein-tui/auth"
+)
+
+// Backend is everything the TUI asks of the Alpstein api. Client is the
+// real implementation, tests and the mock can swap in their own.
+type Backend interface {
+	SetToken(token string)
+	ExchangeAuthKey(ctx context.Context, key string) (string, error)
+	ExchangeCode(ctx context.Context, code, verifier, redirectURI string) (string, error)
+	StartDevice(ctx context.Context) (*auth.DeviceCode, error)
+	PollDevice(ctx context.Context, dc *auth.DeviceCode) (string, error)
+	CurrentUser(ctx context.Context) (*User, error)
+	LiveCryptos(ctx context.Context, page Page) (*AllCryptoResponse, error)
+	CryptoByID(ctx context.Context, id string) (*CryptoModel, error)
+	Trades(ctx context.Context) ([]CryptoModel, error)
+	About(ctx context.Context, symbol string) (*CryptoAbout, error)
+}
+
+type Client struct {
+	baseURL string
+	http    *http.Client
+
+	mu    sync.RWMutex
+	token string
+}
+
+var _ Backend = (*Client)(nil)
+
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+func (c *Client) bearer() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+func (c *Client) ExchangeAuthKey(ctx context.Context, key string) (string, error) {
+	return c.exchange(ctx, url.Values{"auth-key": {key}})
+}
+
+func (c *Client) ExchangeCode(ctx context.Context, code, verifier, redirectURI string) (string, error) {
+	return c.exchange(ctx, url.Values{
+		"code":          {code},
+		"code_verifier": {verifier},
+		"redirect_uri":  {redirectURI},
+	})
+}
+
+func (c *Client) exchange(ctx context.Context, query url.Values) (string, error) {
+	var res HttpResponse
+	if err := c.get(ctx, "exchange auth", "cli-oauth/callback?"+query.Encode(), false, &res); err != nil {
+		return "", err
+	}
+	if res.Data == "" {
+		return "", &DecodeError{Op: "exchange auth", Err: errors.New("no token in response")}
+	}
+	return res.Data, nil
+}
+
+// StartDevice asks for a device login code, the user approves it in a
+// browser on any machine.
+func (c *Client) StartDevice(ctx context.Context) (*auth.DeviceCode, error) {
+	return auth.StartDevice(ctx, c.http, c.baseURL)
+}
+
+// PollDevice waits for the user to approve dc and returns the token.
+func (c *Client) PollDevice(ctx context.Context, dc *auth.DeviceCode) (string, error) {
+	return auth.PollDevice(ctx, c.http, c.baseURL, dc)
+}
+
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.get(ctx, "fetch user", "user", true, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (c *Client) LiveCryptos(ctx context.Context, page Page) (*AllCryptoResponse, error) {
+	q := url.Values{}
+	if page.Action != "" {
+		q.Set("action", page.Action)
+		q.Set("last_seen", fmt.Sprintf("%d|%s", page.Time, page.Id))
+	}
+	if page.Limit > 0 {
+		q.Set("limit", strconv.Itoa(page.Limit))
+	}
+	var res AllCryptoResponse
+	if err := c.get(ctx, "fetch live cryptos", "live-cryptos?"+q.Encode(), true, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) CryptoByID(ctx context.Context, id string) (*CryptoModel, error) {
+	const op = "fetch crypto"
+	var res SingleCryptoResponse
+	if err := c.get(ctx, op, "crypto/"+url.PathEscape(id), true, &res); err != nil {
+		return nil, err
+	}
+	if len(res.Data) == 0 {
+		return nil, &StatusError{Op: op, StatusCode: http.StatusNotFound, Message: "no crypto with id " + id}
+	}
+	var crypto CryptoModel
+	if err := json.Unmarshal(res.Data[0], &crypto); err != nil {
+		return nil, &DecodeError{Op: op, Err: err}
+	}
+	return &crypto, nil
+}
+
+// Trades lists the signals that turned into trades, triggered or closed,
+// newest first.
+func (c *Client) Trades(ctx context.Context) ([]CryptoModel, error) {
+	var res AllCryptoResponse
+	if err := c.get(ctx, "fetch trades", "trades?status=triggered,closed", true, &res); err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+// About is the project description of a coin.
+func (c *Client) About(ctx context.Context, symbol string) (*CryptoAbout, error) {
+	var res AboutResponse
+	if err := c.get(ctx, "fetch about", "crypto-about/"+url.PathEscape(symbol), true, &res); err != nil {
+		return nil, err
+	}
+	return &res.Data, nil
+}
+
+func (c *Client) get(ctx context.Context, op, path string, authed bool, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/"+path, nil)
+	if err != nil {
+		return &NetworkError{Op: op, Err: err}
+	}
+	if authed {
+		req.Header.Set("Authorization", "Bearer "+c.bearer())
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return &NetworkError{Op: op, Err: err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &NetworkError{Op: op, Err: err}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var envelope struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(body, &envelope)
+		return &StatusError{Op: op, StatusCode: resp.StatusCode, Message: envelope.Message}
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return &DecodeError{Op: op, Err: err}
+	}
+	return nil
+}
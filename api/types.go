@@ -0,0 +1,101 @@
+package api
+
+import "encoding/json"
+
+type CryptoModel struct {
+	Id                string  `gorm:"primaryKey" json:"id"`
+	SourceUrl         string  `json:"sourceurl"`
+	Heading           string  `json:"heading"`
+	Name              string  `json:"name"`
+	Symbol            string  `json:"symbol"`
+	Synopsis          string  `json:"synopsis"`
+	Position          string  `json:"position"`
+	Buy               string  `json:"buy"`
+	BuyPrice          float64 `json:"buyprice"`
+	TakeProfit        float64 `json:"takeprofit"`
+	StopLoss          float64 `json:"stoploss"`
+	Sell              string  `json:"sell"`
+	SellPrice         float64 `json:"sellprice"`
+	ShortCoverProfit  float64 `json:"shortcoverprofit,omitempty"`
+	ShortCoverLoss    float64 `json:"shortcoverloss,omitempty"`
+	WaitOut           string  `json:"waitout"`
+	Monitor           string  `json:"monitor"`
+	Tag               string  `json:"tag"`
+	PriceAtCreation   float64 `json:"priceAtCreation"`
+	TriggeredPosition string  `json:"triggeredposition"`
+	Status            string  `json:"status"`
+	ScrappedAt        int64   `json:"scrappedat"`
+	CreatedAt         int64   `json:"createdat"`
+	TriggeredAt       int64   `json:"triggeredat"`
+	ClosureAt         int64   `json:"closureat"`
+	// ExitPrice is where a closed trade got out, 0 while it is open or
+	// when the api doesn't say.
+	ExitPrice float64 `json:"exitprice,omitempty"`
+}
+
+type CryptoQueryMetadata struct {
+	HasNextPage   bool   `json:"hasNextPage"`
+	HasPrevPage   bool   `json:"hasPrevPage"`
+	LastSeenTime  int64  `json:"lastSeenTime"`
+	FirstSeenTime int64  `json:"firstSeenTime"`
+	LastSeenId    string `json:"lastSeenId"`
+	FirstSeenId   string `json:"firstSeenId"`
+}
+
+type AllCryptoResponse struct {
+	Data     []CryptoModel       `json:"data"`
+	Metadata CryptoQueryMetadata `json:"metadata"`
+}
+
+type CryptoAbout struct {
+	Id     string `json:"id"`
+	Symbol string `json:"symbol"`
+	About  string `json:"about"`
+}
+
+type AboutResponse struct {
+	Data CryptoAbout `json:"data"`
+}
+
+type SingleCryptoResponse struct {
+	Data []json.RawMessage `json:"data"`
+}
+
+type UserResType struct {
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	ImageUrl  string `json:"imageUrl"`
+}
+
+type User struct {
+	Message string      `json:"message"`
+	Data    UserResType `json:"data"`
+}
+
+// HttpResponse is the generic envelope most endpoints answer with.
+type HttpResponse struct {
+	Message string `json:"message,omitempty"`
+	Data    string `json:"data,omitempty"`
+}
+
+// Page selects a window of live cryptos. The zero Action loads the newest
+// page, "next" and "prev" walk from the given cursor.
+type Page struct {
+	Action string
+	Limit  int
+	Time   int64
+	Id     string
+}
+
+func FirstPage(limit int) Page {
+	return Page{Limit: limit}
+}
+
+func NextPage(meta CryptoQueryMetadata, limit int) Page {
+	return Page{Action: "next", Limit: limit, Time: meta.LastSeenTime, Id: meta.LastSeenId}
+}
+
+func PrevPage(meta CryptoQueryMetadata, limit int) Page {
+	return Page{Action: "prev", Limit: limit, Time: meta.FirstSeenTime, Id: meta.FirstSeenId}
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mdp/qrterminal/v3"
+)
+
+var (
+	ErrDeviceExpired = errors.New("login code expired, press enter to get a new one")
+	ErrDeviceDenied  = errors.New("login was denied")
+)
+
+// DeviceCode is what the backend hands out when a headless login starts.
+// The field names follow RFC 8628.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type deviceResponse struct {
+	Message string          `json:"message,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func StartDevice(ctx context.Context, client *http.Client, baseURL string) (*DeviceCode, error) {
+	res, status, err := postJSON(ctx, client, endpoint(baseURL, "cli-oauth/device"), nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("starting device login: %s", res.Message)
+	}
+	var dc DeviceCode
+	if err := json.Unmarshal(res.Data, &dc); err != nil {
+		return nil, fmt.Errorf("starting device login: %w", err)
+	}
+	if dc.Interval <= 0 {
+		dc.Interval = 5
+	}
+	return &dc, nil
+}
+
+// PollDevice keeps asking the backend for a token until the user approves
+// the code in a browser, the code expires or ctx is cancelled.
+func PollDevice(ctx context.Context, client *http.Client, baseURL string, dc *DeviceCode) (string, error) {
+	interval := time.Duration(dc.Interval) * time.Second
+	if dc.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(dc.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+	body := map[string]string{"device_code": dc.DeviceCode}
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", ErrDeviceExpired
+			}
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+
+		res, status, err := postJSON(ctx, client, endpoint(baseURL, "cli-oauth/device/token"), body)
+		if err != nil {
+			return "", err
+		}
+		if status == http.StatusOK {
+			var jwt string
+			if err := json.Unmarshal(res.Data, &jwt); err != nil {
+				return "", fmt.Errorf("reading device token: %w", err)
+			}
+			return jwt, nil
+		}
+		switch res.Message {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "expired_token":
+			return "", ErrDeviceExpired
+		case "access_denied":
+			return "", ErrDeviceDenied
+		default:
+			return "", fmt.Errorf("device login failed: %s", res.Message)
+		}
+	}
+}
+
+// QRCode renders the verification url as half block characters so it can
+// be scanned straight off the terminal.
+func (dc *DeviceCode) QRCode() string {
+	target := dc.VerificationURIComplete
+	if target == "" {
+		target = dc.VerificationURI
+	}
+	var b strings.Builder
+	qrterminal.GenerateHalfBlock(target, qrterminal.L, &b)
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func endpoint(baseURL, path string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + path
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body any) (deviceResponse, int, error) {
+	var res deviceResponse
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return res, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(raw))
+	if err != nil {
+		return res, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return res, 0, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return res, resp.StatusCode, fmt.Errorf("decoding %s: %w", url, err)
+	}
+	return res, resp.StatusCode, nil
+}
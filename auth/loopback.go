@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const callbackPath = "/callback"
+
+var ErrStateMismatch = errors.New("oauth state mismatch, login aborted")
+
+// Loopback is a one shot http listener on 127.0.0.1 that receives the
+// oauth redirect, so the user never has to copy an auth key around.
+type Loopback struct {
+	RedirectURI string
+	Verifier    string
+	state       string
+	listener    net.Listener
+	server      *http.Server
+	result      chan callbackResult
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+func StartLoopback() (*Loopback, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("starting callback listener: %w", err)
+	}
+	state, err := randomState()
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+	l := &Loopback{
+		RedirectURI: fmt.Sprintf("http://%s%s", ln.Addr().String(), callbackPath),
+		Verifier:    oauth2.GenerateVerifier(),
+		state:       state,
+		listener:    ln,
+		result:      make(chan callbackResult, 1),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, l.handleCallback)
+	l.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go l.server.Serve(ln)
+	return l, nil
+}
+
+// AuthCodeURL returns the consent url for cfg with this login's redirect,
+// state and PKCE challenge filled in.
+func (l *Loopback) AuthCodeURL(cfg oauth2.Config) string {
+	cfg.RedirectURL = l.RedirectURI
+	return cfg.AuthCodeURL(l.state, oauth2.S256ChallengeOption(l.Verifier))
+}
+
+// Wait blocks until the browser hits the callback or ctx is done and
+// always shuts the listener down before returning.
+func (l *Loopback) Wait(ctx context.Context) (string, error) {
+	defer l.Close()
+	select {
+	case res := <-l.result:
+		return res.code, res.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (l *Loopback) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	l.server.Shutdown(ctx)
+}
+
+func (l *Loopback) handleCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var res callbackResult
+	switch {
+	case q.Get("state") != l.state:
+		res.err = ErrStateMismatch
+	case q.Get("error") != "":
+		res.err = fmt.Errorf("login failed: %s", q.Get("error"))
+	case q.Get("code") == "":
+		res.err = errors.New("login failed: no authorization code returned")
+	default:
+		res.code = q.Get("code")
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if res.err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "<p>Alpstein login failed: %s</p>", html.EscapeString(res.err.Error()))
+	} else {
+		fmt.Fprint(w, "<p>Alpstein login complete, you can close this tab and return to the terminal.</p>")
+	}
+	select {
+	case l.result <- res:
+	default:
+	}
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,108 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+var errNoOpener = errors.New("no browser available")
+
+// Opener launches urls in a browser. Command is an optional template such
+// as "firefox --new-tab {url}"; when it has no {url} placeholder the url
+// is appended as the last argument.
+type Opener struct {
+	Command string
+}
+
+// FallbackError is returned when no browser could be started. The url is
+// copied to the clipboard when possible so the user can paste it instead.
+type FallbackError struct {
+	URL    string
+	Copied bool
+	Err    error
+}
+
+func (e *FallbackError) Error() string {
+	if e.Copied {
+		return fmt.Sprintf("no browser available, link copied to clipboard: %s", e.URL)
+	}
+	return fmt.Sprintf("no browser available, open this link manually: %s", e.URL)
+}
+
+func (e *FallbackError) Unwrap() error {
+	return e.Err
+}
+
+func (o Opener) Open(url string) error {
+	var lastErr error = errNoOpener
+	for _, tpl := range o.candidates() {
+		argv := expand(tpl, url)
+		if len(argv) == 0 {
+			continue
+		}
+		if _, err := exec.LookPath(argv[0]); err != nil {
+			lastErr = err
+			continue
+		}
+		if err := exec.Command(argv[0], argv[1:]...).Start(); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	copied := clipboard.WriteAll(url) == nil
+	return &FallbackError{URL: url, Copied: copied, Err: lastErr}
+}
+
+// candidates lists the command templates to try, most specific first:
+// the configured command, then $BROWSER, then the platform default.
+func (o Opener) candidates() []string {
+	var out []string
+	if o.Command != "" {
+		out = append(out, o.Command)
+	}
+	if env := os.Getenv("BROWSER"); env != "" {
+		out = append(out, strings.Split(env, string(os.PathListSeparator))...)
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		out = append(out, "open")
+	case "windows":
+		out = append(out, "rundll32 url.dll,FileProtocolHandler")
+	default:
+		// without a display xdg-open "succeeds" but nothing shows up,
+		// which is exactly the ssh case we want to fall back on
+		if os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != "" {
+			out = append(out, "xdg-open")
+		}
+		if os.Getenv("WSL_DISTRO_NAME") != "" {
+			out = append(out, "wslview")
+		}
+	}
+	return out
+}
+
+func expand(tpl, url string) []string {
+	fields := strings.Fields(tpl)
+	replaced := false
+	for i, f := range fields {
+		if strings.Contains(f, "{url}") {
+			fields[i] = strings.ReplaceAll(f, "{url}", url)
+			replaced = true
+		} else if strings.Contains(f, "%s") {
+			// $BROWSER entries conventionally use %s
+			fields[i] = strings.ReplaceAll(f, "%s", url)
+			replaced = true
+		}
+	}
+	if !replaced {
+		fields = append(fields, url)
+	}
+	return fields
+}
@@ -0,0 +1,188 @@
+// Package chart draws candlesticks with the box drawing characters a
+// terminal already has, one column per candle.
+package chart
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/whiplashvin/alpstein-tui/market"
+)
+
+// Level is a horizontal price line, e.g. a take profit.
+type Level struct {
+	Label string
+	Price float64
+	Color string
+}
+
+// Marker is a vertical line at a moment, e.g. when a signal triggered.
+type Marker struct {
+	Label string
+	Time  time.Time
+	Color string
+}
+
+type Options struct {
+	Width, Height int
+	Interval      time.Duration
+	Levels        []Level
+	Markers       []Marker
+	Up, Down      string
+	Axis          string
+}
+
+type cell struct {
+	ch    rune
+	color string
+}
+
+// Candles renders the newest candles that fit, a price axis on the right
+// and a legend line for the levels and markers underneath.
+func Candles(candles []market.Candle, o Options) string {
+	axisW := 0
+	for _, c := range candles {
+		axisW = max(axisW, len(market.FormatPrice(c.High)), len(market.FormatPrice(c.Low)))
+	}
+	for _, l := range o.Levels {
+		axisW = max(axisW, len(market.FormatPrice(l.Price)))
+	}
+	plotW := o.Width - axisW - 1
+	height := o.Height - 1 // the legend line
+	if plotW < 4 || height < 3 || len(candles) == 0 {
+		return ""
+	}
+	if len(candles) > plotW {
+		candles = candles[len(candles)-plotW:]
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, c := range candles {
+		lo, hi = min(lo, c.Low), max(hi, c.High)
+	}
+	// levels far off the chart would squash the candles into a line, only
+	// stretch for the ones within half a range either side
+	span := hi - lo
+	var levels []Level
+	for _, l := range o.Levels {
+		if l.Price <= 0 || l.Price < lo-span/2 || l.Price > hi+span/2 {
+			continue
+		}
+		levels = append(levels, l)
+		lo, hi = min(lo, l.Price), max(hi, l.Price)
+	}
+	if hi == lo {
+		hi, lo = hi*1.001+1e-9, lo*0.999
+	}
+	row := func(price float64) int {
+		r := int(math.Round((hi - price) / (hi - lo) * float64(height-1)))
+		return min(max(r, 0), height-1)
+	}
+
+	grid := make([][]cell, height)
+	for r := range grid {
+		grid[r] = make([]cell, plotW)
+		for c := range grid[r] {
+			grid[r][c] = cell{ch: ' '}
+		}
+	}
+	for _, l := range levels {
+		r := row(l.Price)
+		for c := range grid[r] {
+			grid[r][c] = cell{ch: '─', color: l.Color}
+		}
+	}
+	var markers []Marker
+	for _, mk := range o.Markers {
+		col := column(candles, mk.Time, o.Interval)
+		if col < 0 {
+			continue
+		}
+		markers = append(markers, mk)
+		for r := range grid {
+			grid[r][col] = cell{ch: '┊', color: mk.Color}
+		}
+	}
+	for col, c := range candles {
+		color := o.Up
+		if c.Close < c.Open {
+			color = o.Down
+		}
+		for r := row(c.High); r <= row(c.Low); r++ {
+			grid[r][col] = cell{ch: '│', color: color}
+		}
+		top, bottom := row(max(c.Open, c.Close)), row(min(c.Open, c.Close))
+		for r := top; r <= bottom; r++ {
+			grid[r][col] = cell{ch: '┃', color: color}
+		}
+	}
+
+	axis := make([]string, height)
+	axis[0] = paint(market.FormatPrice(hi), o.Axis)
+	axis[height-1] = paint(market.FormatPrice(lo), o.Axis)
+	for _, l := range levels {
+		axis[row(l.Price)] = paint(market.FormatPrice(l.Price), l.Color)
+	}
+
+	var b strings.Builder
+	for r := range grid {
+		b.WriteString(renderRow(grid[r]))
+		b.WriteString(" ")
+		b.WriteString(axis[r])
+		b.WriteString("\n")
+	}
+	b.WriteString(legend(levels, markers))
+	return b.String()
+}
+
+// column is the candle that contains t, -1 when t is off the chart.
+func column(candles []market.Candle, t time.Time, iv time.Duration) int {
+	if t.IsZero() {
+		return -1
+	}
+	for i, c := range candles {
+		if !t.Before(c.Start) && t.Before(c.Start.Add(iv)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderRow styles runs of the same colour together so a row isn't one
+// escape sequence per cell.
+func renderRow(cells []cell) string {
+	var b strings.Builder
+	start := 0
+	for i := 1; i <= len(cells); i++ {
+		if i < len(cells) && cells[i].color == cells[start].color {
+			continue
+		}
+		var run strings.Builder
+		for _, c := range cells[start:i] {
+			run.WriteRune(c.ch)
+		}
+		b.WriteString(paint(run.String(), cells[start].color))
+		start = i
+	}
+	return b.String()
+}
+
+func legend(levels []Level, markers []Marker) string {
+	var parts []string
+	for _, l := range levels {
+		parts = append(parts, paint("─ "+l.Label, l.Color))
+	}
+	for _, mk := range markers {
+		parts = append(parts, paint("┊ "+mk.Label, mk.Color))
+	}
+	return strings.Join(parts, "  ")
+}
+
+func paint(s, color string) string {
+	if color == "" {
+		return s
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(s)
+}
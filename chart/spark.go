@@ -0,0 +1,34 @@
+package chart
+
+import "math"
+
+var bars = []rune("▁▂▃▄▅▆▇█")
+
+// Spark draws values as a one line sparkline at most width cells wide,
+// dropping evenly spaced points when there are more values than cells so
+// the whole span stays on screen.
+func Spark(values []float64, width int, color string) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+	if len(values) > width {
+		picked := make([]float64, width)
+		for i := range picked {
+			picked[i] = values[i*(len(values)-1)/max(width-1, 1)]
+		}
+		values = picked
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	line := make([]rune, len(values))
+	for i, v := range values {
+		level := len(bars) / 2
+		if hi > lo {
+			level = int(math.Round((v - lo) / (hi - lo) * float64(len(bars)-1)))
+		}
+		line[i] = bars[level]
+	}
+	return paint(string(line), color)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type bar struct {
+	start                  time.Time
+	open, high, low, close float64
+}
+
+// parseInterval understands binance's interval names, "1d" included which
+// time.ParseDuration doesn't.
+func parseInterval(s string) time.Duration {
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		days, _ := strconv.Atoi(n)
+		return time.Duration(days) * 24 * time.Hour
+	}
+	d, _ := time.ParseDuration(s)
+	return d
+}
+
+// history walks backwards from the current price, seeded by symbol and
+// interval so reloading shows the same chart.
+func (m *market) history(sym string, iv time.Duration, limit int) []bar {
+	price, _ := m.price(sym)
+	if price == 0 || iv <= 0 {
+		return nil
+	}
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToUpper(sym) + iv.String()))
+	rng := rand.New(rand.NewPCG(h.Sum64(), 0))
+
+	bars := make([]bar, limit)
+	start := time.Now().Truncate(iv)
+	close := price
+	for i := limit - 1; i >= 0; i-- {
+		open := close * (1 + (rng.Float64()-0.5)/50)
+		b := bar{start: start, open: open, close: close}
+		b.high = max(open, close) * (1 + rng.Float64()/200)
+		b.low = min(open, close) * (1 - rng.Float64()/200)
+		bars[i] = b
+		close = open
+		start = start.Add(-iv)
+	}
+	return bars
+}
+
+func limitParam(r *http.Request, def int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || n <= 0 {
+		return def
+	}
+	return min(n, 1000)
+}
+
+func fmtPrice(f float64) string {
+	return strconv.FormatFloat(f, 'f', 8, 64)
+}
+
+// handleBinanceKlines serves /api/v3/klines rows as
+// [open time, open, high, low, close, volume, close time].
+func (s *server) handleBinanceKlines(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	sym := strings.TrimSuffix(strings.ToLower(q.Get("symbol")), "usdt")
+	iv := parseInterval(q.Get("interval"))
+	rows := [][]any{}
+	for _, b := range s.market.history(sym, iv, limitParam(r, 500)) {
+		rows = append(rows, []any{
+			b.start.UnixMilli(), fmtPrice(b.open), fmtPrice(b.high), fmtPrice(b.low), fmtPrice(b.close),
+			"0", b.start.Add(iv).UnixMilli() - 1,
+		})
+	}
+	writeJSON(w, http.StatusOK, rows)
+}
+
+// handleCoinbaseCandles serves rows as [time, low, high, open, close,
+// volume], newest first like coinbase does.
+func (s *server) handleCoinbaseCandles(w http.ResponseWriter, r *http.Request) {
+	sym, _, _ := strings.Cut(r.PathValue("id"), "-")
+	secs, _ := strconv.Atoi(r.URL.Query().Get("granularity"))
+	bars := s.market.history(sym, time.Duration(secs)*time.Second, 300)
+	rows := [][]float64{}
+	for i := len(bars) - 1; i >= 0; i-- {
+		b := bars[i]
+		rows = append(rows, []float64{float64(b.start.Unix()), b.low, b.high, b.open, b.close, 0})
+	}
+	writeJSON(w, http.StatusOK, rows)
+}
+
+// kline is the live bar for a <pair>@kline_<interval> stream, the newest
+// bar of the same walk the rest endpoint serves.
+func (m *market) kline(stream string) []byte {
+	pair, name, _ := strings.Cut(stream, "@kline_")
+	iv := parseInterval(name)
+	bars := m.history(strings.TrimSuffix(pair, "usdt"), iv, 1)
+	if len(bars) == 0 {
+		return nil
+	}
+	b := bars[0]
+	// E, T and L share a letter with e, t and l, the client has to keep
+	// them apart
+	raw, _ := json.Marshal(map[string]any{
+		"e": "kline",
+		"E": time.Now().UnixMilli(),
+		"s": strings.ToUpper(pair),
+		"k": map[string]any{
+			"t": b.start.UnixMilli(),
+			"T": b.start.Add(iv).UnixMilli() - 1,
+			"L": time.Now().UnixNano(),
+			"i": name,
+			"o": fmtPrice(b.open),
+			"h": fmtPrice(b.high),
+			"l": fmtPrice(b.low),
+			"c": fmtPrice(b.close),
+			"x": false,
+		},
+	})
+	return raw
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// article is a news page with everything a real one has around the text:
+// scripts, navigation, a cookie banner, share buttons and a footer. The
+// reader tests serve the BTC page from reader/testdata/article.html.
+var article = template.Must(template.New("article").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} traders eye a breakout | Mock Crypto News</title>
+<meta property="og:title" content="{{.Name}} traders eye a breakout as funding flips">
+<meta property="og:site_name" content="Mock Crypto News">
+<style>body { font-family: sans-serif; }</style>
+<script>window.analytics = { track: function () {} };</script>
+</head>
+<body>
+<div class="cookie-banner">We use cookies. <button>Accept</button></div>
+<header>
+  <a href="/">Mock Crypto News</a>
+  <nav><a href="/markets">Markets</a> <a href="/defi">DeFi</a> <a href="/policy">Policy</a></nav>
+</header>
+<main>
+  <article>
+    <h1>{{.Name}} traders eye a breakout as funding flips</h1>
+    <div class="byline">By <a href="/authors/jane">Jane Doe</a> · 4 min read</div>
+    <figure><img src="/chart.png" alt="chart"><figcaption>{{.Symbol}} on the 4h chart</figcaption></figure>
+    <p>{{.Name}} has spent most of the week pinned under the top of its range, but the
+       derivatives market is starting to lean the other way. Funding on the largest
+       perpetual venues flipped positive on Tuesday for the first time this month.</p>
+    <p>Open interest rose <strong>12%</strong> over the same stretch while spot volume
+       picked up on the majors, a combination that has preceded the last two breakouts.</p>
+    <h2>What the desks are watching</h2>
+    <ul>
+      <li>a daily close above the range high</li>
+      <li>whether funding stays positive into the weekend</li>
+      <li>exchange outflows, which have been rising since Monday</li>
+    </ul>
+    <blockquote>"Positioning is still light, a clean break could run further than people
+       expect," one trader said.</blockquote>
+    <p>Not everyone is convinced. A failed breakout would leave late longs trapped above
+       the range and could send {{.Symbol}} back to the lows it tested last month.</p>
+    <div class="share"><button>Share</button> <button>Tweet</button></div>
+  </article>
+  <aside><h3>Most read</h3><ul><li><a href="/1">Unrelated story</a></li></ul></aside>
+</main>
+<footer>© Mock Crypto News · <a href="/terms">Terms</a></footer>
+<script>analytics.track("view");</script>
+</body>
+</html>
+`))
+
+// handleArticle serves the fixture article for a coin, the news reader in
+// the TUI is tried against it.
+func (s *server) handleArticle(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(r.PathValue("slug"))
+	for _, c := range coins {
+		if c.symbol == symbol {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			article.Execute(w, map[string]string{"Name": c.name, "Symbol": c.symbol})
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
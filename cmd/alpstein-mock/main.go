@@ -0,0 +1,235 @@
+// alpstein-mock is a local stand-in for the Alpstein backend and the
+// binance and coinbase ticker streams and candle history. It also serves
+// the news article behind every signal, cluttered like a real news page,
+// under /fixtures/article. Point the TUI at it to try everything without
+// touching production:
+//
+//	alpstein-tui -backend-url http://localhost:8787/ \
+//		-ws-url ws://localhost:8787/ws -binance-ws-url ws://localhost:8787/binance \
+//		-coinbase-ws-url ws://localhost:8787/coinbase \
+//		-binance-rest-url http://localhost:8787/binance -coinbase-rest-url http://localhost:8787/coinbase
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+type server struct {
+	addr        string
+	tokenTTL    time.Duration
+	autoApprove time.Duration
+
+	mu      sync.Mutex
+	devices map[string]*pendingDevice
+	market  *market
+}
+
+type pendingDevice struct {
+	userCode  string
+	expiresAt time.Time
+	approved  bool
+	lastPoll  time.Time
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:8787", "listen address")
+	ttl := flag.Duration("token-ttl", time.Hour, "lifetime of issued tokens")
+	autoApprove := flag.Duration("auto-approve", 0, "approve device logins automatically after this long (0 waits for /device)")
+	flag.Parse()
+
+	s := &server{
+		addr:        *addr,
+		tokenTTL:    *ttl,
+		autoApprove: *autoApprove,
+		devices:     map[string]*pendingDevice{},
+		market:      newMarket(*addr),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /cli-oauth/callback", s.handleCallback)
+	mux.HandleFunc("POST /cli-oauth/device", s.handleDeviceStart)
+	mux.HandleFunc("POST /cli-oauth/device/token", s.handleDeviceToken)
+	mux.HandleFunc("GET /device", s.handleDevicePage)
+	mux.HandleFunc("POST /device", s.handleDeviceApprove)
+	mux.HandleFunc("GET /user", s.handleUser)
+	mux.HandleFunc("GET /live-cryptos", s.handleLiveCryptos)
+	mux.HandleFunc("GET /crypto/{id}", s.handleCryptoByID)
+	mux.HandleFunc("GET /trades", s.handleTrades)
+	mux.HandleFunc("GET /crypto-about/{symbol}", s.handleAbout)
+	mux.HandleFunc("GET /fixtures/article/{slug}", s.handleArticle)
+	mux.HandleFunc("GET /ws", s.handleSignalWS)
+	mux.HandleFunc("GET /binance/ws/{stream}", s.handleBinanceWS)
+	mux.HandleFunc("GET /binance/stream", s.handleBinanceStream)
+	mux.HandleFunc("GET /binance/api/v3/klines", s.handleBinanceKlines)
+	mux.HandleFunc("GET /coinbase", s.handleCoinbaseWS)
+	mux.HandleFunc("GET /coinbase/products/{id}/candles", s.handleCoinbaseCandles)
+
+	log.Printf("alpstein-mock listening on http://%s/", *addr)
+	log.Fatal(http.ListenAndServe(*addr, cleanPath(mux)))
+}
+
+// cleanPath folds the double slashes the TUI produces when BACKEND_URL
+// ends in "/" without redirecting, so POST bodies survive.
+func cleanPath(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = path.Clean(r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) handleCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("auth-key") == "" && q.Get("code") == "" {
+		writeJSON(w, http.StatusNotFound, map[string]any{"message": "invalid auth key"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"message": "ok", "data": s.issueToken()})
+}
+
+func (s *server) handleDeviceStart(w http.ResponseWriter, r *http.Request) {
+	deviceCode := randomHex(16)
+	userCode := strings.ToUpper(randomHex(2) + "-" + randomHex(2))
+	dev := &pendingDevice{userCode: userCode, expiresAt: time.Now().Add(10 * time.Minute)}
+
+	s.mu.Lock()
+	s.devices[deviceCode] = dev
+	s.mu.Unlock()
+
+	if s.autoApprove > 0 {
+		time.AfterFunc(s.autoApprove, func() {
+			s.mu.Lock()
+			dev.approved = true
+			s.mu.Unlock()
+		})
+	}
+
+	verify := fmt.Sprintf("http://%s/device", s.addr)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"message": "ok",
+		"data": map[string]any{
+			"device_code":               deviceCode,
+			"user_code":                 userCode,
+			"verification_uri":          verify,
+			"verification_uri_complete": verify + "?user_code=" + userCode,
+			"expires_in":                600,
+			"interval":                  2,
+		},
+	})
+}
+
+func (s *server) handleDeviceToken(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		DeviceCode string `json:"device_code"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dev, ok := s.devices[body.DeviceCode]
+	switch {
+	case !ok:
+		writeJSON(w, http.StatusBadRequest, map[string]any{"message": "invalid_grant"})
+	case time.Now().After(dev.expiresAt):
+		delete(s.devices, body.DeviceCode)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"message": "expired_token"})
+	case time.Since(dev.lastPoll) < time.Second:
+		dev.lastPoll = time.Now()
+		writeJSON(w, http.StatusBadRequest, map[string]any{"message": "slow_down"})
+	case !dev.approved:
+		dev.lastPoll = time.Now()
+		writeJSON(w, http.StatusBadRequest, map[string]any{"message": "authorization_pending"})
+	default:
+		delete(s.devices, body.DeviceCode)
+		writeJSON(w, http.StatusOK, map[string]any{"message": "ok", "data": s.issueToken()})
+	}
+}
+
+func (s *server) handleDevicePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, `<form method="post"><input name="user_code" placeholder="XXXX-XXXX"> <button>Approve</button></form>`)
+}
+
+func (s *server) handleDeviceApprove(w http.ResponseWriter, r *http.Request) {
+	code := strings.ToUpper(strings.TrimSpace(r.FormValue("user_code")))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, dev := range s.devices {
+		if dev.userCode == code {
+			dev.approved = true
+			fmt.Fprint(w, "approved, go back to the terminal")
+			return
+		}
+	}
+	http.Error(w, "unknown code", http.StatusNotFound)
+}
+
+func (s *server) handleUser(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"message": "ok",
+		"data": map[string]any{
+			"email":     "dev@alpstein.local",
+			"firstName": "Dev",
+			"lastName":  "Mock",
+		},
+	})
+}
+
+// issueToken returns an unsigned JWT shaped token. Only the exp claim
+// matters to the TUI.
+func (s *server) issueToken() string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	claims, _ := json.Marshal(map[string]any{
+		"sub": "dev@alpstein.local",
+		"exp": time.Now().Add(s.tokenTTL).Unix(),
+	})
+	return header + "." + enc.EncodeToString(claims) + "." + randomHex(8)
+}
+
+func (s *server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return false
+	}
+	return time.Now().Unix() < claims.Exp
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,515 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type signal struct {
+	Id                string  `json:"id"`
+	SourceUrl         string  `json:"sourceurl"`
+	Heading           string  `json:"heading"`
+	Name              string  `json:"name"`
+	Symbol            string  `json:"symbol"`
+	Synopsis          string  `json:"synopsis"`
+	Position          string  `json:"position"`
+	Buy               string  `json:"buy"`
+	BuyPrice          float64 `json:"buyprice"`
+	TakeProfit        float64 `json:"takeprofit"`
+	StopLoss          float64 `json:"stoploss"`
+	Sell              string  `json:"sell"`
+	SellPrice         float64 `json:"sellprice"`
+	ShortCoverProfit  float64 `json:"shortcoverprofit,omitempty"`
+	ShortCoverLoss    float64 `json:"shortcoverloss,omitempty"`
+	WaitOut           string  `json:"waitout"`
+	Monitor           string  `json:"monitor"`
+	Tag               string  `json:"tag"`
+	PriceAtCreation   float64 `json:"priceAtCreation"`
+	TriggeredPosition string  `json:"triggeredposition"`
+	Status            string  `json:"status"`
+	ScrappedAt        int64   `json:"scrappedat"`
+	CreatedAt         int64   `json:"createdat"`
+	TriggeredAt       int64   `json:"triggeredat"`
+	ClosureAt         int64   `json:"closureat"`
+	ExitPrice         float64 `json:"exitprice,omitempty"`
+}
+
+var coins = []struct {
+	symbol, name string
+	price        float64
+}{
+	{"BTC", "Bitcoin", 97000}, {"ETH", "Ethereum", 3400}, {"SOL", "Solana", 190},
+	{"XRP", "XRP", 2.3}, {"DOGE", "Dogecoin", 0.32}, {"ADA", "Cardano", 0.95},
+	{"AVAX", "Avalanche", 38}, {"LINK", "Chainlink", 22}, {"DOT", "Polkadot", 7.1},
+	{"LTC", "Litecoin", 105}, {"PEPE", "Pepe", 0.000018}, {"SUI", "Sui", 4.2},
+	{"NEAR", "Near", 5.3}, {"ATOM", "Cosmos", 6.8}, {"TRX", "Tron", 0.25},
+	{"BNB", "BNB", 690}, {"UNI", "Uniswap", 13},
+}
+
+type market struct {
+	mu      sync.Mutex
+	signals []signal
+	prices  map[string]float64
+	open    map[string]float64
+}
+
+// newMarket makes up a signal per coin. Their news links point back at
+// addr, where the fixture articles are served.
+func newMarket(addr string) *market {
+	m := &market{prices: map[string]float64{}, open: map[string]float64{}}
+	now := time.Now()
+	statuses := []string{"pending", "triggered", "triggered", "closed", "pending"}
+	positions := []string{"long", "short", "long", "unclear"}
+	for i, c := range coins {
+		m.prices[c.symbol] = c.price
+		m.open[c.symbol] = c.price * (1 + (rand.Float64()-0.5)/20)
+		created := now.Add(-time.Duration(i*3+1) * time.Hour)
+		s := signal{
+			Id:               fmt.Sprintf("mock%02d", i),
+			SourceUrl:        "http://" + addr + "/fixtures/article/" + strings.ToLower(c.symbol),
+			Heading:          fmt.Sprintf("%s traders eye a breakout as funding flips and open interest climbs into the weekly close", c.name),
+			Name:             c.name,
+			Symbol:           c.symbol,
+			Synopsis:         fmt.Sprintf("## Summary\n%s has been consolidating under resistance while **spot volume** rises.\n\n- funding turned positive\n- open interest up 12%%\n- whales accumulating on dips\n\nA clean break above the range high would confirm the move.", c.name),
+			Position:         positions[i%len(positions)],
+			Buy:              "on a retest of the range high",
+			BuyPrice:         round(c.price * 1.01),
+			TakeProfit:       round(c.price * 1.08),
+			StopLoss:         round(c.price * 0.96),
+			Sell:             "on a loss of the range low",
+			SellPrice:        round(c.price * 0.99),
+			ShortCoverProfit: round(c.price * 0.92),
+			ShortCoverLoss:   round(c.price * 1.04),
+			WaitOut:          "skip the trade if it chops inside the range for more than a day",
+			Monitor:          "funding, open interest and the 4h close above the range high",
+			Tag:              []string{"breakout,momentum", "news,macro", "onchain", "reversal,risky"}[i%4],
+			PriceAtCreation:  c.price,
+			Status:           statuses[i%len(statuses)],
+			ScrappedAt:       created.Add(-10 * time.Minute).UnixMilli(),
+			CreatedAt:        created.UnixMilli(),
+		}
+		if s.Status != "pending" {
+			s.TriggeredPosition = "long"
+			if s.Position == "short" {
+				s.TriggeredPosition = "short"
+			}
+			s.TriggeredAt = created.Add(40 * time.Minute).UnixMilli()
+		}
+		if s.Status == "closed" {
+			s.ClosureAt = created.Add(150 * time.Minute).UnixMilli()
+			// every other closed trade is stopped out
+			s.ExitPrice = s.TakeProfit
+			if s.TriggeredPosition == "short" {
+				s.ExitPrice = s.ShortCoverProfit
+			}
+			if i%2 == 0 {
+				s.ExitPrice = s.StopLoss
+				if s.TriggeredPosition == "short" {
+					s.ExitPrice = s.ShortCoverLoss
+				}
+			}
+		}
+		m.signals = append(m.signals, s)
+	}
+	sort.Slice(m.signals, func(i, j int) bool { return m.signals[i].CreatedAt > m.signals[j].CreatedAt })
+	go m.walk()
+	return m
+}
+
+// walk nudges every price a little each second so the live widgets move.
+func (m *market) walk() {
+	for range time.Tick(time.Second) {
+		m.mu.Lock()
+		for sym, p := range m.prices {
+			m.prices[sym] = p * (1 + (rand.Float64()-0.5)/500)
+		}
+		m.mu.Unlock()
+	}
+}
+
+func (m *market) price(symbol string) (float64, float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.prices[strings.ToUpper(symbol)], m.open[strings.ToUpper(symbol)]
+}
+
+func (s *server) handleLiveCryptos(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 {
+		limit = 8
+	}
+	all := s.market.signals
+	start, end := 0, min(limit, len(all))
+	if anchor := q.Get("last_seen"); anchor != "" {
+		_, id, _ := strings.Cut(anchor, "|")
+		idx := -1
+		for i, sig := range all {
+			if sig.Id == id {
+				idx = i
+			}
+		}
+		switch q.Get("action") {
+		case "next":
+			start = idx + 1
+			end = min(start+limit, len(all))
+		case "prev":
+			end = max(idx, 0)
+			start = max(end-limit, 0)
+		}
+	}
+	page := all[start:end]
+	meta := map[string]any{
+		"hasNextPage": end < len(all),
+		"hasPrevPage": start > 0,
+	}
+	if len(page) > 0 {
+		meta["firstSeenTime"] = page[0].CreatedAt
+		meta["firstSeenId"] = page[0].Id
+		meta["lastSeenTime"] = page[len(page)-1].CreatedAt
+		meta["lastSeenId"] = page[len(page)-1].Id
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": page, "metadata": meta})
+}
+
+func (s *server) handleCryptoByID(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+	for _, sig := range s.market.signals {
+		if sig.Id == r.PathValue("id") {
+			writeJSON(w, http.StatusOK, map[string]any{"data": []signal{sig}})
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]any{"message": "crypto not found", "data": []signal{}})
+}
+
+// handleTrades lists the signals with one of the statuses asked for,
+// triggered and closed ones when none are.
+func (s *server) handleTrades(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+	statuses := strings.Split(r.URL.Query().Get("status"), ",")
+	if r.URL.Query().Get("status") == "" {
+		statuses = []string{"triggered", "closed"}
+	}
+	trades := []signal{}
+	for _, sig := range s.market.signals {
+		if slices.Contains(statuses, sig.Status) {
+			trades = append(trades, sig)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": trades})
+}
+
+// handleAbout describes the coin behind a symbol, long enough that the
+// TUI has to wrap it.
+func (s *server) handleAbout(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"message": "unauthorized"})
+		return
+	}
+	symbol := strings.ToUpper(r.PathValue("symbol"))
+	for i, c := range coins {
+		if c.symbol != symbol {
+			continue
+		}
+		about := fmt.Sprintf("%s (%s) is a decentralised network secured by its own validators and traded against every major quote currency. "+
+			"The project started as an answer to slow and expensive settlement and has since grown an ecosystem of wallets, exchanges and applications built on top of it.\n\n"+
+			"Supply is capped by the protocol and new coins enter circulation on a fixed schedule, which is why traders watch issuance, "+
+			"exchange balances and funding as closely as the price itself.", c.name, c.symbol)
+		writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"id": fmt.Sprintf("about%02d", i), "symbol": c.symbol, "about": about}})
+		return
+	}
+	writeJSON(w, http.StatusNotFound, map[string]any{"message": "no description for " + symbol})
+}
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+// handleSignalWS mimics ws.alpstein.tech: a SUB message picks the signal
+// whose P&L gets streamed back every second.
+func (s *server) handleSignalWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var mu sync.Mutex
+	subs := map[string]bool{}
+	go func() {
+		for {
+			var msg struct {
+				Event   string `json:"event"`
+				Payload string `json:"payload"`
+			}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			mu.Lock()
+			switch msg.Event {
+			case "SUB":
+				subs[msg.Payload] = true
+			case "UNSUB":
+				delete(subs, msg.Payload)
+			}
+			mu.Unlock()
+		}
+	}()
+	for range time.Tick(time.Second) {
+		mu.Lock()
+		ids := make([]string, 0, len(subs))
+		for id := range subs {
+			ids = append(ids, id)
+		}
+		mu.Unlock()
+		for _, id := range ids {
+			sig, ok := s.market.find(id)
+			if !ok || sig.Status != "triggered" {
+				continue
+			}
+			price, _ := s.market.price(sig.Symbol)
+			entry := sig.BuyPrice
+			change := (price - entry) / entry * 100
+			if sig.TriggeredPosition == "short" {
+				entry = sig.SellPrice
+				change = (entry - price) / entry * 100
+			}
+			kind := "profit"
+			if change < 0 {
+				kind = "loss"
+			}
+			if err := conn.WriteJSON(map[string]any{"id": id, "kind": kind, "value": round(math.Abs(change))}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (m *market) find(id string) (signal, bool) {
+	for _, sig := range m.signals {
+		if sig.Id == id {
+			return sig, true
+		}
+	}
+	return signal{}, false
+}
+
+// handleBinanceWS serves "<sym>usdt@ticker" in the same shape binance does.
+func (s *server) handleBinanceWS(w http.ResponseWriter, r *http.Request) {
+	stream := r.PathValue("stream")
+	sym, _, _ := strings.Cut(stream, "@")
+	sym = strings.TrimSuffix(sym, "usdt")
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	for range time.Tick(time.Second) {
+		if err := conn.WriteMessage(websocket.TextMessage, s.market.ticker(sym)); err != nil {
+			log.Println("binance mock:", err)
+			return
+		}
+	}
+}
+
+// handleBinanceStream serves the combined form, /stream?streams=a@ticker/b@kline_1h/c@depth20/d@aggTrade,
+// wrapping each event in the {"stream","data"} envelope.
+func (s *server) handleBinanceStream(w http.ResponseWriter, r *http.Request) {
+	streams := strings.Split(r.URL.Query().Get("streams"), "/")
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	for range time.Tick(time.Second) {
+		for _, stream := range streams {
+			var data []byte
+			switch sym, name, _ := strings.Cut(stream, "@"); {
+			case strings.HasPrefix(name, "kline_"):
+				data = s.market.kline(stream)
+			case strings.HasPrefix(name, "depth"):
+				data = s.market.depth(strings.TrimSuffix(sym, "usdt"))
+			case name == "aggTrade":
+				data = s.market.aggTrade(strings.TrimSuffix(sym, "usdt"))
+			default:
+				data = s.market.ticker(strings.TrimSuffix(sym, "usdt"))
+			}
+			if data == nil {
+				continue
+			}
+			frame := map[string]any{"stream": stream, "data": json.RawMessage(data)}
+			if err := conn.WriteJSON(frame); err != nil {
+				log.Println("binance mock:", err)
+				return
+			}
+		}
+	}
+}
+
+// handleCoinbaseWS mimics the coinbase exchange feed: subscribe and
+// unsubscribe messages pick the products sent on the ticker and matches
+// channels.
+func (s *server) handleCoinbaseWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var mu sync.Mutex
+	// keyed by channel and product, e.g. "ticker BTC-USD"
+	subs := map[string]bool{}
+	go func() {
+		for {
+			var msg struct {
+				Type       string   `json:"type"`
+				ProductIDs []string `json:"product_ids"`
+				Channels   []string `json:"channels"`
+			}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			mu.Lock()
+			for _, ch := range msg.Channels {
+				for _, id := range msg.ProductIDs {
+					subs[ch+" "+id] = msg.Type == "subscribe"
+				}
+			}
+			mu.Unlock()
+		}
+	}()
+	for range time.Tick(time.Second) {
+		mu.Lock()
+		var keys []string
+		for key, on := range subs {
+			if on {
+				keys = append(keys, key)
+			}
+		}
+		mu.Unlock()
+		for _, key := range keys {
+			ch, id, _ := strings.Cut(key, " ")
+			sym, _, _ := strings.Cut(id, "-")
+			price, open := s.market.price(sym)
+			msg := map[string]any{
+				"type":       "ticker",
+				"product_id": id,
+				"price":      strconv.FormatFloat(price, 'f', 8, 64),
+				"open_24h":   strconv.FormatFloat(open, 'f', 8, 64),
+				"time":       time.Now().UTC().Format(time.RFC3339Nano),
+			}
+			if ch == "matches" {
+				size, buy := s.market.trade(price)
+				side := "buy"
+				if buy {
+					side = "sell"
+				}
+				msg = map[string]any{
+					"type":       "match",
+					"product_id": id,
+					"price":      strconv.FormatFloat(price, 'f', 8, 64),
+					"size":       strconv.FormatFloat(size, 'f', 8, 64),
+					"side":       side,
+					"time":       time.Now().UTC().Format(time.RFC3339Nano),
+				}
+			}
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (m *market) ticker(sym string) []byte {
+	price, open := m.price(sym)
+	raw, _ := json.Marshal(map[string]any{
+		"e": "24hrTicker",
+		"E": time.Now().UnixMilli(),
+		"s": strings.ToUpper(sym) + "USDT",
+		"p": strconv.FormatFloat(price-open, 'f', 8, 64),
+		"P": strconv.FormatFloat((price-open)/open*100, 'f', 3, 64),
+		"c": strconv.FormatFloat(price, 'f', 8, 64),
+		"C": time.Now().UnixMilli(),
+	})
+	return raw
+}
+
+func round(f float64) float64 {
+	if f < 1 {
+		return f
+	}
+	return math.Round(f*100) / 100
+}
+
+// depth is a made up partial book around the current price, 20 levels a
+// side like <pair>@depth20.
+func (m *market) depth(sym string) []byte {
+	price, _ := m.price(sym)
+	if price == 0 {
+		return nil
+	}
+	step := price * 0.0002
+	var bids, asks [][]string
+	for i := range 20 {
+		bid := price - step*float64(i+1)
+		ask := price + step*float64(i+1)
+		bids = append(bids, []string{fmtPrice(bid), fmtPrice(rand.Float64() * 5000 / price)})
+		asks = append(asks, []string{fmtPrice(ask), fmtPrice(rand.Float64() * 5000 / price)})
+	}
+	raw, _ := json.Marshal(map[string]any{
+		"lastUpdateId": time.Now().UnixNano(),
+		"bids":         bids,
+		"asks":         asks,
+	})
+	return raw
+}
+
+// trade makes up a print at price, now and then a large one so the tape
+// has something to highlight.
+func (m *market) trade(price float64) (size float64, buy bool) {
+	size = rand.Float64() * 2000 / price
+	if rand.N(8) == 0 {
+		size *= 100
+	}
+	return size, rand.N(2) == 0
+}
+
+func (m *market) aggTrade(sym string) []byte {
+	price, _ := m.price(sym)
+	if price == 0 {
+		return nil
+	}
+	size, buy := m.trade(price)
+	raw, _ := json.Marshal(map[string]any{
+		"e": "aggTrade",
+		"E": time.Now().UnixMilli(),
+		"s": strings.ToUpper(sym) + "USDT",
+		"p": fmtPrice(price),
+		"q": fmtPrice(size),
+		"T": time.Now().UnixMilli(),
+		"m": !buy,
+		"M": true,
+	})
+	return raw
+}
@@ -0,0 +1,371 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"github.com/whiplashvin/alpstein-tui/creds"
+	"github.com/whiplashvin/alpstein-tui/market"
+)
+
+const fileName = "config.toml"
+
+// Config is every setting the TUI reads. Each field can come from the
+// config file (toml tag), a .env file or the environment (env tag) and a
+// command line flag (flag tag), in increasing order of precedence.
+type Config struct {
+	BackendURL      string        `toml:"backend_url" env:"BACKEND_URL" flag:"backend-url" usage:"base url of the alpstein api"`
+	OAuthClient     string        `toml:"oauth_client" env:"OAUTH_CLIENT" flag:"oauth-client" usage:"google oauth client id"`
+	OAuthCallback   string        `toml:"oauth_callback" env:"OAUTH_CB" flag:"oauth-callback" usage:"oauth redirect url for the paste login"`
+	Login           string        `toml:"login" env:"ALPSTEIN_LOGIN" flag:"login" default:"paste" usage:"login flow: paste, loopback or device"`
+	Browser         string        `toml:"browser" env:"ALPSTEIN_BROWSER" flag:"browser" usage:"command used to open links, e.g. \"firefox --new-tab {url}\""`
+	WSURL           string        `toml:"ws_url" env:"ALPSTEIN_WS_URL" flag:"ws-url" default:"wss://ws.alpstein.tech" usage:"websocket that streams live signal P&L"`
+	WSOrigin        string        `toml:"ws_origin" env:"ALPSTEIN_WS_ORIGIN" flag:"ws-origin" default:"https://alpstein.tech" usage:"Origin header sent to ws_url"`
+	BinanceWSURL    string        `toml:"binance_ws_url" env:"ALPSTEIN_BINANCE_WS_URL" flag:"binance-ws-url" default:"wss://stream.binance.com:9443" usage:"binance market stream host"`
+	BinanceRESTURL  string        `toml:"binance_rest_url" env:"ALPSTEIN_BINANCE_REST_URL" flag:"binance-rest-url" default:"https://api.binance.com" usage:"binance rest api, used for chart history"`
+	CoinbaseWSURL   string        `toml:"coinbase_ws_url" env:"ALPSTEIN_COINBASE_WS_URL" flag:"coinbase-ws-url" default:"wss://ws-feed.exchange.coinbase.com" usage:"coinbase exchange websocket feed"`
+	CoinbaseRESTURL string        `toml:"coinbase_rest_url" env:"ALPSTEIN_COINBASE_REST_URL" flag:"coinbase-rest-url" default:"https://api.exchange.coinbase.com" usage:"coinbase exchange rest api, used for chart history"`
+	Market          string        `toml:"market_provider" env:"ALPSTEIN_MARKET_PROVIDER" flag:"market-provider" default:"binance" usage:"where live prices come from: binance or coinbase"`
+	Quote           string        `toml:"quote_currency" env:"ALPSTEIN_QUOTE_CURRENCY" flag:"quote-currency" default:"USDT" usage:"currency live prices are quoted in"`
+	MarketOverrides string        `toml:"market_overrides" env:"ALPSTEIN_MARKET_OVERRIDES" flag:"market-overrides" usage:"per coin provider and quote, e.g. \"XYZ=coinbase:USD,ABC=binance\""`
+	Heartbeat       time.Duration `toml:"heartbeat_interval" env:"ALPSTEIN_HEARTBEAT_INTERVAL" flag:"heartbeat-interval" default:"20s" usage:"how often to ping the websockets"`
+	ReadTimeout     time.Duration `toml:"read_timeout" env:"ALPSTEIN_READ_TIMEOUT" flag:"read-timeout" default:"60s" usage:"drop a websocket that has been silent this long"`
+	StaleAfter      time.Duration `toml:"stale_after" env:"ALPSTEIN_STALE_AFTER" flag:"stale-after" default:"15s" usage:"grey out live prices older than this"`
+	LargeTrade      float64       `toml:"large_trade" env:"ALPSTEIN_LARGE_TRADE" flag:"large-trade" default:"50000" usage:"highlight trades worth at least this much in the quote currency, 0 turns it off"`
+	Passphrase      string        `toml:"passphrase" env:"ALPSTEIN_PASSPHRASE" flag:"-" secret:"true" usage:"encrypts the stored token"`
+}
+
+// Result is a resolved Config plus where each value came from.
+type Result struct {
+	Config
+	Profile       string
+	ProfileSource string
+	File          string
+	FileFound     bool
+	Sources       map[string]string
+	Unknown       []string
+}
+
+// Flags holds the command line side of the config, register it before
+// parsing and call Resolve afterwards.
+type Flags struct {
+	fs      *flag.FlagSet
+	profile *string
+	file    *string
+	values  map[string]*rawValue
+}
+
+type rawValue struct {
+	s      string
+	isBool bool
+}
+
+func (v *rawValue) String() string     { return v.s }
+func (v *rawValue) Set(s string) error { v.s = s; return nil }
+func (v *rawValue) IsBoolFlag() bool   { return v.isBool }
+
+type setting struct {
+	key    string
+	env    string
+	flag   string
+	def    string
+	usage  string
+	secret bool
+	index  int
+	kind   reflect.Type
+}
+
+var settings = loadSettings()
+
+func loadSettings() []setting {
+	t := reflect.TypeOf(Config{})
+	out := make([]setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		s := setting{
+			key:    f.Tag.Get("toml"),
+			env:    f.Tag.Get("env"),
+			flag:   f.Tag.Get("flag"),
+			def:    f.Tag.Get("default"),
+			usage:  f.Tag.Get("usage"),
+			secret: f.Tag.Get("secret") == "true",
+			index:  i,
+			kind:   f.Type,
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{
+		fs:      fs,
+		profile: fs.String("profile", "", "named profile, each keeps its own login, .env and [profiles.<name>] settings"),
+		file:    fs.String("config", "", "path to the config file"),
+		values:  map[string]*rawValue{},
+	}
+	for _, s := range settings {
+		if s.flag == "-" {
+			continue
+		}
+		v := &rawValue{isBool: s.kind.Kind() == reflect.Bool}
+		f.values[s.key] = v
+		fs.Var(v, s.flag, s.usage)
+	}
+	return f
+}
+
+// Resolve layers defaults, the config file, its profile section, .env
+// files, the environment and finally flags, later layers winning.
+func (f *Flags) Resolve() (*Result, error) {
+	r := &Result{Sources: map[string]string{}}
+	set := map[string]bool{}
+	f.fs.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+
+	for _, s := range settings {
+		if s.def != "" {
+			if err := r.apply(s, s.def, "default"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	r.File = *f.file
+	if r.File == "" {
+		r.File = os.Getenv("ALPSTEIN_CONFIG")
+	}
+	if r.File == "" {
+		dir, err := creds.ConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		r.File = filepath.Join(dir, fileName)
+	}
+	file := map[string]any{}
+	if _, err := toml.DecodeFile(r.File, &file); err == nil {
+		r.FileFound = true
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading %s: %w", r.File, err)
+	}
+
+	r.Profile, r.ProfileSource = creds.DefaultProfile, "default"
+	if p, ok := file["profile"].(string); ok && p != "" {
+		r.Profile, r.ProfileSource = p, "file"
+	}
+	if p := os.Getenv("ALPSTEIN_PROFILE"); p != "" {
+		r.Profile, r.ProfileSource = p, "env ALPSTEIN_PROFILE"
+	}
+	if set["profile"] {
+		r.Profile, r.ProfileSource = *f.profile, "flag -profile"
+	}
+	profileDir, err := creds.ProfileDir(r.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.applyTable(file, "file "+r.File, "profiles", "profile"); err != nil {
+		return nil, err
+	}
+	if profiles, ok := file["profiles"].(map[string]any); ok {
+		if section, ok := profiles[r.Profile].(map[string]any); ok {
+			if err := r.applyTable(section, fmt.Sprintf("file %s [profiles.%s]", r.File, r.Profile)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// the cwd .env goes first so the profile's own .env can override it
+	for _, path := range []string{".env", filepath.Join(profileDir, ".env")} {
+		vars, err := godotenv.Read(path)
+		if err != nil {
+			continue
+		}
+		for _, s := range settings {
+			if v, ok := vars[s.env]; ok {
+				if err := r.apply(s, v, ".env "+path); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(s.env); ok {
+			if err := r.apply(s, v, "env "+s.env); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if set[s.flag] {
+			if err := r.apply(s, f.values[s.key].s, "flag -"+s.flag); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+func (r *Result) applyTable(table map[string]any, source string, skip ...string) error {
+	known := map[string]setting{}
+	for _, s := range settings {
+		known[s.key] = s
+	}
+outer:
+	for k, v := range table {
+		for _, sk := range skip {
+			if k == sk {
+				continue outer
+			}
+		}
+		s, ok := known[k]
+		if !ok || s.secret {
+			r.Unknown = append(r.Unknown, fmt.Sprintf("%s (%s)", k, source))
+			continue
+		}
+		if err := r.apply(s, fmt.Sprint(v), source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Result) apply(s setting, raw, source string) error {
+	field := reflect.ValueOf(&r.Config).Elem().Field(s.index)
+	if err := setValue(field, raw); err != nil {
+		return fmt.Errorf("%s from %s: %w", s.key, source, err)
+	}
+	r.Sources[s.key] = source
+	return nil
+}
+
+func setValue(field reflect.Value, raw string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// Validate returns every problem found, an empty slice means the config
+// is usable.
+func (r *Result) Validate() []string {
+	var problems []string
+	for _, u := range r.Unknown {
+		problems = append(problems, "unknown setting "+u)
+	}
+	switch r.Login {
+	case "paste", "loopback", "device":
+	default:
+		problems = append(problems, fmt.Sprintf("login must be paste, loopback or device, got %q", r.Login))
+	}
+	if r.BackendURL == "" {
+		problems = append(problems, "backend_url is not set")
+	} else if u, err := url.Parse(r.BackendURL); err != nil || u.Scheme == "" || u.Host == "" {
+		problems = append(problems, fmt.Sprintf("backend_url %q is not an absolute url", r.BackendURL))
+	}
+	for key, raw := range map[string]string{"ws_url": r.WSURL, "binance_ws_url": r.BinanceWSURL, "coinbase_ws_url": r.CoinbaseWSURL} {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s %q must be a ws:// or wss:// url", key, raw))
+		}
+	}
+	for key, raw := range map[string]string{"binance_rest_url": r.BinanceRESTURL, "coinbase_rest_url": r.CoinbaseRESTURL} {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s %q must be an http:// or https:// url", key, raw))
+		}
+	}
+	if !slices.Contains(market.Names, strings.ToLower(r.Market)) {
+		problems = append(problems, fmt.Sprintf("market_provider must be one of %s, got %q", strings.Join(market.Names, ", "), r.Market))
+	}
+	if _, err := market.ParseOverrides(r.MarketOverrides); err != nil {
+		problems = append(problems, err.Error())
+	}
+	for key, d := range map[string]time.Duration{"heartbeat_interval": r.Heartbeat, "read_timeout": r.ReadTimeout, "stale_after": r.StaleAfter} {
+		if d <= 0 {
+			problems = append(problems, fmt.Sprintf("%s must be positive, got %s", key, d))
+		}
+	}
+	if r.LargeTrade < 0 {
+		problems = append(problems, fmt.Sprintf("large_trade must not be negative, got %g", r.LargeTrade))
+	}
+	if r.Heartbeat > 0 && r.ReadTimeout > 0 && r.Heartbeat >= r.ReadTimeout {
+		problems = append(problems, fmt.Sprintf("heartbeat_interval %s should be shorter than read_timeout %s", r.Heartbeat, r.ReadTimeout))
+	}
+	if r.Login != "device" && r.OAuthClient == "" {
+		problems = append(problems, "oauth_client is not set")
+	}
+	if r.Login == "paste" && r.OAuthCallback == "" {
+		problems = append(problems, "oauth_callback is not set")
+	}
+	return problems
+}
+
+// Show lists the effective settings and where each came from, secrets
+// are masked.
+func (r *Result) Show() string {
+	var b strings.Builder
+	found := "not found"
+	if r.FileFound {
+		found = "found"
+	}
+	fmt.Fprintf(&b, "%-20s %s (%s)\n", "config file", r.File, found)
+	fmt.Fprintf(&b, "%-20s %-40s %s\n\n", "profile", r.Profile, r.ProfileSource)
+
+	rows := make([]setting, len(settings))
+	copy(rows, settings)
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].key < rows[j].key })
+	v := reflect.ValueOf(r.Config)
+	for _, s := range rows {
+		val := fmt.Sprint(v.Field(s.index).Interface())
+		if s.secret && val != "" {
+			val = "********"
+		}
+		source := r.Sources[s.key]
+		if source == "" {
+			source = "unset"
+		}
+		fmt.Fprintf(&b, "%-20s %-40s %s\n", s.key, val, source)
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package creds
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// TokenExpiry reads the exp claim of a JWT without verifying it, the
+// backend stays the authority on whether a token is still good.
+func TokenExpiry(jwt string) (time.Time, bool) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(raw, &claims); err != nil || claims.Exp == "" {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
+
+// Expired reports whether jwt carries an exp claim that is already past.
+// Tokens without one are treated as valid until the backend says otherwise.
+func Expired(jwt string) bool {
+	exp, ok := TokenExpiry(jwt)
+	return ok && !time.Now().Before(exp)
+}
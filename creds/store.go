@@ -0,0 +1,198 @@
+package creds
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const (
+	appDir         = "alpstein"
+	profilesDir    = "profiles"
+	credsFileName  = "credentials.json"
+	kdfIterations  = 600000
+	DefaultProfile = "default"
+)
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+var (
+	ErrNotFound      = errors.New("no stored credentials")
+	ErrPassphrase    = errors.New("stored credentials are encrypted, set ALPSTEIN_PASSPHRASE")
+	ErrBadPassphrase = errors.New("could not decrypt stored credentials, wrong passphrase?")
+	ErrBadProfile    = errors.New("profile names may only contain letters, digits, '-' and '_'")
+)
+
+// Store keeps the session token on disk so a login survives restarts.
+// When a passphrase is set the token is sealed with AES-GCM.
+type Store struct {
+	Profile    string
+	Path       string
+	passphrase string
+}
+
+type fileContents struct {
+	Jwt        string `json:"jwt,omitempty"`
+	Salt       []byte `json:"salt,omitempty"`
+	Nonce      []byte `json:"nonce,omitempty"`
+	Ciphertext []byte `json:"ciphertext,omitempty"`
+	SavedAt    int64  `json:"savedAt"`
+}
+
+func NewStore(profile, passphrase string) (*Store, error) {
+	dir, err := ProfileDir(profile)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		Profile:    profile,
+		Path:       filepath.Join(dir, credsFileName),
+		passphrase: passphrase,
+	}, nil
+}
+
+func ConfigDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locating config dir: %w", err)
+	}
+	return filepath.Join(base, appDir), nil
+}
+
+// ProfileDir is where a profile keeps its credentials and .env. The
+// default profile lives in the config dir itself so installs from before
+// profiles existed keep their login.
+func ProfileDir(profile string) (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	if profile == "" || profile == DefaultProfile {
+		return dir, nil
+	}
+	if !profileName.MatchString(profile) {
+		return "", ErrBadProfile
+	}
+	return filepath.Join(dir, profilesDir, profile), nil
+}
+
+// Profiles lists the default profile plus every named one found on disk.
+func Profiles() ([]string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, profilesDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && profileName.MatchString(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+func (s *Store) Exists() bool {
+	_, err := os.Stat(s.Path)
+	return err == nil
+}
+
+func (s *Store) Load() (string, error) {
+	raw, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	var f fileContents
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return "", fmt.Errorf("reading %s: %w", s.Path, err)
+	}
+	if f.Ciphertext == nil {
+		if f.Jwt == "" {
+			return "", ErrNotFound
+		}
+		return f.Jwt, nil
+	}
+	if s.passphrase == "" {
+		return "", ErrPassphrase
+	}
+	gcm, err := s.cipher(f.Salt)
+	if err != nil {
+		return "", err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Ciphertext, nil)
+	if err != nil {
+		return "", ErrBadPassphrase
+	}
+	return string(plain), nil
+}
+
+func (s *Store) Save(jwt string) error {
+	f := fileContents{SavedAt: time.Now().UnixMilli()}
+	if s.passphrase == "" {
+		f.Jwt = jwt
+	} else {
+		f.Salt = make([]byte, 16)
+		if _, err := rand.Read(f.Salt); err != nil {
+			return err
+		}
+		gcm, err := s.cipher(f.Salt)
+		if err != nil {
+			return err
+		}
+		f.Nonce = make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(f.Nonce); err != nil {
+			return err
+		}
+		f.Ciphertext = gcm.Seal(nil, f.Nonce, []byte(jwt), nil)
+	}
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return err
+	}
+	// write to a temp file first so a crash never leaves a half written token
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+func (s *Store) Clear() error {
+	err := os.Remove(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *Store) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, kdfIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,108 @@
+package dash
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/whiplashvin/alpstein-tui/api"
+)
+
+// about is the description of one coin, kept for the session once it
+// loaded. A failed fetch is tried again the next time the tab shows it.
+type about struct {
+	text    string
+	loading bool
+	err     string
+}
+
+// AboutLoaded carries a coin's description, or why it couldn't be fetched.
+type AboutLoaded struct {
+	symbol string
+	About  string
+	Err    error
+}
+
+func aboutKey(symbol string) string {
+	return strings.ToUpper(symbol)
+}
+
+// loadAbout fetches the selected coin's description, only while the About
+// tab is open and only if it isn't cached already.
+func (m *model) loadAbout() tea.Cmd {
+	if m.tab != aboutTab || m.CurrCrypto.Symbol == "" {
+		return nil
+	}
+	key := aboutKey(m.CurrCrypto.Symbol)
+	if a, ok := m.abouts[key]; ok && a.err == "" {
+		return nil
+	}
+	m.abouts[key] = &about{loading: true}
+	m.syncAbout()
+	client, symbol := m.client, m.CurrCrypto.Symbol
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		res, err := client.About(ctx, symbol)
+		if err != nil {
+			return AboutLoaded{symbol: key, Err: err}
+		}
+		return AboutLoaded{symbol: key, About: res.About}
+	}
+}
+
+func (m *model) aboutLoaded(msg AboutLoaded) tea.Cmd {
+	switch {
+	case msg.Err == nil:
+		m.abouts[msg.symbol] = &about{text: strings.TrimSpace(msg.About)}
+	case api.IsNotFound(msg.Err):
+		// nothing to show, and nothing to gain from asking again
+		m.abouts[msg.symbol] = &about{}
+	case api.IsUnauthorized(msg.Err):
+		m.abouts[msg.symbol] = &about{err: "session expired"}
+		m.syncAbout()
+		return func() tea.Msg { return requestFailed(reqAbout, msg.Err, false) }
+	default:
+		log.Println("about:", msg.Err)
+		m.abouts[msg.symbol] = &about{err: msg.Err.Error()}
+	}
+	m.syncAbout()
+	return nil
+}
+
+// syncAbout puts the selected coin's description in the About viewport,
+// wrapped to the pane.
+func (m *model) syncAbout() {
+	width := m.layout().body - 4
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor))
+	var text string
+	a, ok := m.abouts[aboutKey(m.CurrCrypto.Symbol)]
+	switch {
+	case !ok || a.loading:
+		text = dim.Render("loading the description...")
+	case a.err != "":
+		text = lipgloss.NewStyle().Foreground(lipgloss.Color("#fb2c36")).Render("couldn't load the description: "+a.err) +
+			dim.Render("  [r] retry")
+	case a.text == "":
+		text = dim.Render("no description for " + m.CurrCrypto.Symbol)
+	default:
+		title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(m.primaryTextColor)).
+			Render(m.CurrCrypto.Name + " (" + strings.ToUpper(m.CurrCrypto.Symbol) + ")")
+		text = title + "\n\n" + a.text
+	}
+	content := lipgloss.NewStyle().Width(max(width-4, 20)).MarginLeft(2).
+		Foreground(lipgloss.Color(m.secondaryTextColor)).Render(text)
+	key := m.CurrCrypto.Symbol + "|" + content
+	if key == m.aboutShown && width == m.about.Width {
+		return
+	}
+	if !strings.HasPrefix(m.aboutShown, m.CurrCrypto.Symbol+"|") {
+		m.about.GotoTop()
+	}
+	m.aboutShown = key
+	m.about.Width = width
+	m.about.SetContent(content)
+}
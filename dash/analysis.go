@@ -0,0 +1,86 @@
+package dash
+
+import (
+	"hash/fnv"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/whiplashvin/alpstein-tui/api"
+)
+
+// tagColors are the chip backgrounds, a tag always gets the same one.
+var tagColors = []string{"#a3b3ff", "#00bc7d", "#ffb900", "#ff6467", "#c7d8ff"}
+
+// syncAnalysis renders the selected signal's analysis into the viewport,
+// only when the signal or the pane width changed since the last time.
+func (m *model) syncAnalysis() {
+	width := m.layout().body - 4
+	source := analysisSource(m.CurrCrypto)
+	if source == m.analysisSource && width == m.analysis.Width {
+		return
+	}
+	if source != m.analysisSource {
+		m.analysis.GotoTop()
+	}
+	m.analysisSource = source
+	m.analysis.Width = width
+	m.analysis.SetContent(renderMarkdown(source, width))
+}
+
+// analysisSource is the markdown of the analysis tab: the synopsis, the
+// entry conditions of the plans on offer and what to watch.
+func analysisSource(c api.CryptoModel) string {
+	var b strings.Builder
+	synopsis := strings.TrimSpace(c.Synopsis)
+	if synopsis == "" {
+		synopsis = "_no synopsis for this signal_"
+	}
+	b.WriteString(synopsis)
+	if c.Position != "short" && c.Buy != "" {
+		b.WriteString("\n\n### Buy\n" + c.Buy)
+	}
+	if c.Position != "long" && c.Sell != "" {
+		b.WriteString("\n\n### Sell\n" + c.Sell)
+	}
+	if c.Monitor != "" {
+		b.WriteString("\n\n### Monitor\n" + c.Monitor)
+	}
+	if c.WaitOut != "" {
+		b.WriteString("\n\n### Wait out\n" + c.WaitOut)
+	}
+	return b.String()
+}
+
+func renderMarkdown(source string, width int) string {
+	r, err := glamour.NewTermRenderer(glamour.WithStandardStyle("dark"), glamour.WithWordWrap(max(width-4, 20)))
+	if err != nil {
+		log.Println("analysis:", err)
+		return source
+	}
+	out, err := r.Render(source)
+	if err != nil {
+		log.Println("analysis:", err)
+		return source
+	}
+	return strings.Trim(out, "\n")
+}
+
+// renderTags is the comma separated tags as coloured chips.
+func (m *model) renderTags(tag string) string {
+	var chips []string
+	for _, t := range strings.Split(tag, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		h := fnv.New32a()
+		h.Write([]byte(strings.ToLower(t)))
+		chips = append(chips, lipgloss.NewStyle().Padding(0, 1).
+			Foreground(lipgloss.Color("#18181b")).
+			Background(lipgloss.Color(tagColors[h.Sum32()%uint32(len(tagColors))])).
+			Render(t))
+	}
+	return strings.Join(chips, " ")
+}
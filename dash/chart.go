@@ -0,0 +1,133 @@
+package dash
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/whiplashvin/alpstein-tui/chart"
+	"github.com/whiplashvin/alpstein-tui/feed"
+	"github.com/whiplashvin/alpstein-tui/market"
+)
+
+// chartCandles is how much history the REST seed asks for, enough to fill
+// a wide terminal.
+const chartCandles = 200
+
+// CandlesLoaded carries the REST seed for the chart. key says which
+// symbol and interval it was for so a late answer can be dropped.
+type CandlesLoaded struct {
+	key     string
+	Candles []market.Candle
+	Err     error
+}
+
+// loadChart seeds the chart for the selected coin over REST and points the
+// chart stream at the venue's candle topic. It does nothing when the chart
+// already shows that coin at that interval.
+func (m *model) loadChart() tea.Cmd {
+	symbol := m.CurrCrypto.Symbol
+	if symbol == "" {
+		return nil
+	}
+	iv := market.Intervals[m.interval]
+	key := symbol + "|" + string(iv)
+	if key == m.chartKey {
+		return nil
+	}
+	m.chartKey = key
+	m.candles = nil
+	m.chartErr = ""
+
+	route := m.market.Route(symbol)
+	charting, ok := route.Venue.(market.Charting)
+	if !ok {
+		m.chartErr = "no chart data on " + route.Venue.Name()
+		return nil
+	}
+	m.useVenue(feed.Chart, route.Venue)
+	m.gens[feed.Chart] = m.feeds.Subscribe(feed.Chart, []string{charting.CandleTopic(route.Base, route.Quote, iv)})
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		candles, err := charting.Candles(ctx, route.Base, route.Quote, iv, chartCandles)
+		return CandlesLoaded{key: key, Candles: candles, Err: err}
+	}
+}
+
+// updateChart moves the newest candle with whatever the chart stream
+// carries, a full kline or just a ticker to fold in.
+func (m *model) updateChart(data any) {
+	iv := market.Intervals[m.interval]
+	switch data := data.(type) {
+	case market.Candle:
+		m.candles = market.Merge(m.candles, data)
+	case market.Ticker:
+		m.candles = market.Fold(m.candles, data, iv)
+	}
+	if len(m.candles) > chartCandles {
+		m.candles = m.candles[len(m.candles)-chartCandles:]
+	}
+}
+
+func (m *model) renderChart(width, height int) string {
+	var tabs []string
+	for i, iv := range market.Intervals {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor))
+		if i == m.interval {
+			style = style.Foreground(lipgloss.Color(m.primaryTextColor))
+		}
+		tabs = append(tabs, style.Render(fmt.Sprintf("[%d] %s", i+1, iv)))
+	}
+	header := strings.Join(tabs, " ")
+
+	body := ""
+	switch {
+	case m.chartErr != "":
+		body = m.chartErr
+	case len(m.candles) == 0:
+		body = "loading chart..."
+	default:
+		body = chart.Candles(m.candles, chart.Options{
+			Width:    width,
+			Height:   height - 1,
+			Interval: market.Intervals[m.interval].Duration(),
+			Levels:   m.chartLevels(),
+			Markers: []chart.Marker{
+				{Label: "created", Time: unixMilli(m.CurrCrypto.CreatedAt), Color: m.primaryTextColor},
+				{Label: "triggered", Time: unixMilli(m.CurrCrypto.TriggeredAt), Color: "#e12afb"},
+			},
+			Up:   "#00c950",
+			Down: "#fb2c36",
+			Axis: m.tertiaryTextColor,
+		})
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).Render(header + "\n" + body)
+}
+
+// chartLevels follows the side shown in Agent's Opinion, a short reads
+// its entry and exits from the sell side fields.
+func (m *model) chartLevels() []chart.Level {
+	c := m.CurrCrypto
+	entry, tp, sl := c.BuyPrice, c.TakeProfit, c.StopLoss
+	if m.PositionDisplayed == "short" {
+		entry, tp, sl = c.SellPrice, c.ShortCoverProfit, c.ShortCoverLoss
+	}
+	return []chart.Level{
+		{Label: "entry", Price: entry, Color: "#ffb900"},
+		{Label: "take profit", Price: tp, Color: "#00bc7d"},
+		{Label: "stop loss", Price: sl, Color: "#ff6467"},
+		{Label: "at creation", Price: c.PriceAtCreation, Color: m.secondaryTextColor},
+	}
+}
+
+func unixMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
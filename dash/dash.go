@@ -1,81 +1,36 @@
 package dash
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"net/http"
-	"os/exec"
+	"math"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/gorilla/websocket"
+	"github.com/whiplashvin/alpstein-tui/api"
+	"github.com/whiplashvin/alpstein-tui/browser"
+	"github.com/whiplashvin/alpstein-tui/creds"
+	"github.com/whiplashvin/alpstein-tui/feed"
+	"github.com/whiplashvin/alpstein-tui/market"
 )
 
-type CryptoModel struct {
-	Id		          string        `gorm:"primaryKey" json:"id"`
-	SourceUrl         string        `json:"sourceurl"`
-	Heading           string        `json:"heading"`
-	Name   	          string        `json:"name"`
-	Symbol            string        `json:"symbol"`
-	Synopsis          string        `json:"synopsis"`
-	Position          string        `json:"position"`
-	Buy               string        `json:"buy"`
-	BuyPrice          float64       `json:"buyprice"`
-	TakeProfit        float64       `json:"takeprofit"`
-	StopLoss          float64       `json:"stoploss"`
-	Sell	          string        `json:"sell"`
-	SellPrice  	      float64       `json:"sellprice"`
-	ShortCoverProfit  float64       `json:"shortcoverprofit,omitempty"`
-	ShortCoverLoss    float64       `json:"shortcoverloss,omitempty"`
-	WaitOut           string        `json:"waitout"`
-	Monitor           string        `json:"monitor"`
-	Tag               string        `json:"tag"`
-	PriceAtCreation   float64       `json:"priceAtCreation"`
-	TriggeredPosition string        `json:"triggeredposition"`
-	Status            string        `json:"status"`
-	ScrappedAt        int64         `json:"scrappedat"`
-	CreatedAt         int64         `json:"createdat"`
-	TriggeredAt		  int64			`json:"triggeredat"`
-	ClosureAt		  int64			`json:"closureat"`
-}
 type WSMsg struct{
 	Event string `json:"event"`
 	Payload string `json:"payload"`
 }
+// WSResp is a P&L frame from the signal socket. Id names the signal it is
+// about, a backend that leaves it out can only be trusted with one
+// subscription at a time.
 type WSResp struct{
+	Id string `json:"id,omitempty"`
 	Kind string `json:"kind"`
 	Value float64 `json:"value"`
 }
-type BianceWSResp struct {
-	PriceChange        json.Number `json:"p"`
-	PriceChangePercent json.Number `json:"P"`
-	LastPrice          json.Number `json:"c"`
-	CloseTime          json.Number `json:"C"` 
-}
 
-type CryptoQueryMetadata struct{
-	HasNextPage   bool   `json:"hasNextPage"`
-	HasPrevPage   bool   `json:"hasPrevPage"`
-	LastSeenTime  int64  `json:"lastSeenTime"`
-	FirstSeenTime int64  `json:"firstSeenTime"`
-	LastSeenId    string `json:"lastSeenId"`
-	FirstSeenId   string `json:"firstSeenId"`
-}
-type AllCryptoResponse struct{
-	Data 	 []CryptoModel 		 `json:"data"`
-	Metadata CryptoQueryMetadata `json:"metadata"`
-}
-type CryptoAbout struct{
-	Id 	   string `json:"id"`
-	Symbol string `json:"symbol"`
-	About  string `json:"about"`
-}
-type SingleCryptoResponse struct{
-	Data []json.RawMessage `json:"data"`
-}
 type model struct{
 	ScreenName string
 	// bgColor string
@@ -85,47 +40,150 @@ type model struct{
 	borderColor string
 	Width int
 	Height int
-	Jwt interface{}
-	Url string
+	Jwt string
+	client api.Backend
 	Cursor int
 	CurrUser string
-	Cryptos []CryptoModel
+	Cryptos []api.CryptoModel
 	CurrCryptoId string
 	ErrorModel tea.Model
-	CurrCrypto CryptoModel
+	CurrCrypto api.CryptoModel
 	PositionDisplayed string
-	QueryMetada CryptoQueryMetadata
+	QueryMetada api.CryptoQueryMetadata
 	debounceID int
-	WSConn *websocket.Conn
 	WSRes WSResp
-	BinanceWSConn *websocket.Conn
-	BinanceWSRes BianceWSResp
+	PriceRes market.Ticker
+	opener browser.Opener
+	wsUrl string
+	wsOrigin string
+	market *market.Router
+	Status string
+	statusIsErr bool
+	statusID int
+	tokenExpiry time.Time
+	pending request
+	failed request
+	loaded bool
+	feeds *feed.Manager
+	gens map[feed.Kind]uint64
+	feedStates map[feed.Kind]feed.StatusMsg
+	pnl map[string]WSResp
+	watched []string
+	// tagged is set once a frame named its signal, the socket is shared
+	// with the whole page from then on
+	tagged bool
+	// following is the one signal the socket streams while frames are
+	// untagged
+	following string
+	tickers map[string]market.Ticker
+	seen map[string]time.Time
+	heartbeat time.Duration
+	readTimeout time.Duration
+	staleAfter time.Duration
+	interval int
+	candles []market.Candle
+	chartKey string
+	chartErr string
+	venues map[feed.Kind]market.MarketData
+	sparks map[string]*spark
+	showDepth bool
+	book market.Book
+	depthErr string
+	tape []market.Trade
+	tapeErr string
+	largeTrade float64
+	tab detailTab
+	analysis viewport.Model
+	analysisSource string
+	about viewport.Model
+	aboutShown string
+	abouts map[string]*about
 }
 
+// request names a backend call so it can be replayed once the user has
+// signed in again.
+type request int
+
+const (
+	reqNone request = iota
+	reqLive
+	reqNext
+	reqPrev
+	reqByID
+	reqAbout
+)
+
 type DebounceFetch struct {
 	id int
 }
 type LiveCryptosLoaded struct {
-	Cryptos  []CryptoModel
-	Metadata CryptoQueryMetadata
+	Cryptos  []api.CryptoModel
+	Metadata api.CryptoQueryMetadata
 }
-type Cryptos []CryptoModel
-type QueryMetada CryptoQueryMetadata 
+type Cryptos []api.CryptoModel
+type QueryMetada api.CryptoQueryMetadata 
 type SetCryptoId string
-type SetCurrCrypto CryptoModel
+type SetCurrCrypto api.CryptoModel
 type PositionDisplayed string
-type WSConnected struct {
-	Conn *websocket.Conn
+type StatusMsg struct {
+	Text string
+	Err bool
+}
+type ClearStatus struct {
+	id int
 }
-type BinanceWSConnected struct {
-	Conn *websocket.Conn
+type ReauthRequired struct {
+	retry request
 }
-type WSRespSingnal WSResp
-type BinanceWSRespSingnal BianceWSResp
+
+// FetchFailed reports a backend call that went wrong. Fatal ones mean the
+// dashboard has nothing to show and belong on the error screen, the rest
+// are shown as a toast and can be retried with [r].
+type FetchFailed struct {
+	Err   error
+	Fatal bool
+	retry request
+}
+
+func (f FetchFailed) Error() string {
+	return f.Err.Error()
+}
+type TokenRefreshed struct {
+	Jwt string
+}
+type ExpiryTick struct{}
+type StaleTick struct{}
+// ReadNews asks for the news reader on the selected signal's article.
+type ReadNews struct {
+	URL string
+}
+// WatchSignals adds signals off the page to the P&L subscription, the
+// trades screen streams its open trades over the same socket.
+type WatchSignals []string
 
 
-func InitDash(jwt string,url,currUser string,width,height int)*model{
-	return &model{
+// Options carries the endpoints and helpers the dashboard needs, all of
+// them come from config so the whole thing can run against a mock.
+type Options struct {
+	API api.Backend
+	WSUrl string
+	WSOrigin string
+	Market *market.Router
+	Opener browser.Opener
+	Feeds *feed.Manager
+	Heartbeat time.Duration
+	ReadTimeout time.Duration
+	StaleAfter time.Duration
+	LargeTrade float64
+}
+
+func InitDash(jwt string,currUser string,width,height int,opts Options)*model{
+	exp, _ := creds.TokenExpiry(jwt)
+	feeds := opts.Feeds
+	if feeds == nil {
+		feeds = feed.New()
+	}
+	m := &model{
 		ScreenName: "Dash",
 		// bgColor: "#18181b",
 		primaryTextColor: "#a3b3ff",
@@ -135,83 +193,226 @@ func InitDash(jwt string,url,currUser string,width,height int)*model{
 		Width: width,
 		Height: height,
 		Jwt: jwt,
-		Url: url,
+		client: opts.API,
 		CurrUser: currUser,
 		PositionDisplayed: "long",
+		opener: opts.Opener,
+		wsUrl: opts.WSUrl,
+		wsOrigin: opts.WSOrigin,
+		market: opts.Market,
+		tokenExpiry: exp,
+		gens: map[feed.Kind]uint64{},
+		feedStates: map[feed.Kind]feed.StatusMsg{},
+		pnl: map[string]WSResp{},
+		tickers: map[string]market.Ticker{},
+		seen: map[string]time.Time{},
+		sparks: map[string]*spark{},
+		venues: map[feed.Kind]market.MarketData{},
+		heartbeat: opts.Heartbeat,
+		readTimeout: opts.ReadTimeout,
+		staleAfter: opts.StaleAfter,
+		largeTrade: opts.LargeTrade,
+		interval: 2,
+		analysis: viewport.New(0, 0),
+		about: viewport.New(0, 0),
+		abouts: map[string]*about{},
+		feeds: feeds,
 	}
+	m.configureFeeds()
+	return m
 }
 
 func (m model)Init()tea.Cmd{
-	return tea.Batch(m.FetchLiveCryptos())
+	return tea.Batch(m.FetchLiveCryptos(),expiryTick(),staleTick())
 }
 func (m model)Update(msg tea.Msg)(tea.Model,tea.Cmd){
 	switch msg := msg.(type){
 	case PositionDisplayed:
 		m.PositionDisplayed = string(msg)
+	case StatusMsg:
+		m.Status = msg.Text
+		m.statusIsErr = msg.Err
+		m.statusID++
+		id := m.statusID
+		return m, tea.Tick(8 * time.Second, func(time.Time) tea.Msg {
+			return ClearStatus{id: id}
+		})
+	case ClearStatus:
+		if msg.id == m.statusID {
+			m.Status = ""
+		}
+	case ReauthRequired:
+		if msg.retry != reqNone {
+			m.pending = msg.retry
+		}
+	case WatchSignals:
+		m.watched = msg
+		m.subscribe()
+		return m, nil
+	case TokenRefreshed:
+		m.Jwt = msg.Jwt
+		m.tokenExpiry, _ = creds.TokenExpiry(msg.Jwt)
+		retry := m.pending
+		m.pending = reqNone
+		return m, m.replay(retry)
+	case ExpiryTick:
+		if !m.tokenExpiry.IsZero() && !time.Now().Before(m.tokenExpiry) {
+			return m, tea.Batch(expiryTick(), func() tea.Msg { return ReauthRequired{} })
+		}
+		return m, expiryTick()
 	case DebounceFetch:
 		if msg.id != m.debounceID {
 			return m, nil
 		}
+		if m.Cursor >= len(m.Cryptos) {
+			return m, nil
+		}
+		id := m.Cryptos[m.Cursor].Id
 		return m, func() tea.Msg {
-			return SetCryptoId(m.Cryptos[m.Cursor].Id)
-		}
-	case WSConnected:
-		if m.WSConn != nil {
-			m.WSConn.Close()
-		}
-		m.WSConn = msg.Conn
-		return m, m.readFromWSS()
-	case WSRespSingnal:
-		m.WSRes = WSResp(msg)
-		return m, m.readFromWSS()
-
-	case BinanceWSConnected:
-		if m.BinanceWSConn != nil{
-			m.BinanceWSConn.Close()
-		}
-		m.BinanceWSConn = msg.Conn
-		return m, m.readFromBinanceWSS()
-	case BinanceWSRespSingnal:
-		m.BinanceWSRes = BianceWSResp(msg)
-		return m, m.readFromBinanceWSS()
+			return SetCryptoId(id)
+		}
+	case feed.StatusMsg:
+		// a stream we have since replaced can still report in
+		if msg.Gen != m.gens[msg.Kind] {
+			return m, nil
+		}
+		m.feedStates[msg.Kind] = msg
+		return m, nil
+	case StaleTick:
+		// nothing to update, the redraw is what greys out old data
+		return m, staleTick()
+	case CandlesLoaded:
+		if msg.key != m.chartKey {
+			return m, nil
+		}
+		if msg.Err != nil {
+			log.Println("chart:", msg.Err)
+			m.chartErr = "chart unavailable: " + msg.Err.Error()
+			return m, nil
+		}
+		// keep anything the stream added while the seed was in flight
+		candles := msg.Candles
+		for _, c := range m.candles {
+			candles = market.Merge(candles, c)
+		}
+		m.candles = candles
+		return m, nil
+	case SparkLoaded:
+		m.sparkLoaded(msg)
+		return m, nil
+	case AboutLoaded:
+		return m, m.aboutLoaded(msg)
+	case feed.Frame:
+		if msg.Gen != m.gens[msg.Kind] {
+			return m, nil
+		}
+		if msg.Kind == feed.Chart {
+			m.updateChart(msg.Data)
+			return m, nil
+		}
+		switch data := msg.Data.(type) {
+		case market.Book:
+			m.book = data
+			return m, nil
+		case market.Trade:
+			m.addTrade(data)
+			return m, nil
+		}
+		m.seen[msg.Topic] = msg.At
+		switch data := msg.Data.(type) {
+		case WSResp:
+			if data.Id == "" {
+				// only the selected signal is subscribed while frames are
+				// untagged, anything else could be about a signal we left
+				if !m.tagged {
+					m.WSRes = data
+					// and it is what the as of marker looks up
+					m.seen[m.CurrCryptoId] = msg.At
+				}
+				return m, nil
+			}
+			if !m.tagged {
+				m.tagged = true
+				m.subscribe()
+			}
+			m.pnl[data.Id] = data
+			if data.Id == m.CurrCryptoId {
+				m.WSRes = data
+			}
+		case market.Ticker:
+			m.tickers[data.Topic] = data
+			m.foldSpark(data)
+			if data.Topic == m.priceTopic(m.CurrCrypto.Symbol) {
+				m.PriceRes = data
+			}
+		}
+		return m, nil
 	case SetCryptoId:
 		m.CurrCryptoId = string(msg)
 		cmd := m.fetchCryptoByID()
 		return m, cmd
 	case SetCurrCrypto:	
-		m.CurrCrypto = CryptoModel(msg)
+		m.CurrCrypto = api.CryptoModel(msg)
 		if m.CurrCrypto.Position == "unclear"{
 			m.PositionDisplayed = "long"
 		}else{
 			m.PositionDisplayed = m.CurrCrypto.Position
 		}
-		cmd1 := m.connectToWS()
-		cmd2 := m.connectToBinanceWs()
-		return m, tea.Batch(cmd1,cmd2)
+		m.WSRes = m.pnl[m.CurrCrypto.Id]
+		m.PriceRes = m.tickers[m.priceTopic(m.CurrCrypto.Symbol)]
+		m.subscribe()
+		m.watchPrices()
+		m.watchDepth()
+		m.watchTrades()
+		m.syncTabs()
+		return m, tea.Batch(m.loadChart(), m.loadAbout())
+	case FetchFailed:
+		m.failed = msg.retry
+		m.Status = msg.Error() + "  [r] retry"
+		m.statusIsErr = true
+		m.statusID++
+		return m, nil
 	case LiveCryptosLoaded:
+		m.loaded = true
+		m.failed = reqNone
 		m.Cryptos = msg.Cryptos
-		m.CurrCryptoId = m.Cryptos[0].Id
-		m.CurrCrypto = m.Cryptos[0]
 		m.QueryMetada = msg.Metadata
 		m.Cursor = 0
+		if len(m.Cryptos) == 0 {
+			m.CurrCryptoId = ""
+			m.CurrCrypto = api.CryptoModel{}
+			m.subscribe()
+			m.watchPrices()
+			m.watchDepth()
+			m.watchTrades()
+			return m, m.loadSparks()
+		}
+		m.CurrCryptoId = m.Cryptos[0].Id
+		m.CurrCrypto = m.Cryptos[0]
 		if m.CurrCrypto.Position == "unclear"{
 			m.PositionDisplayed = "long"
 		}else{
 			m.PositionDisplayed = m.CurrCrypto.Position
 		}
-		cmd1 := m.connectToWS()
-		cmd2 := m.connectToBinanceWs()
-		return m, tea.Batch(cmd1,cmd2)
+		m.WSRes = m.pnl[m.CurrCrypto.Id]
+		m.PriceRes = m.tickers[m.priceTopic(m.CurrCrypto.Symbol)]
+		m.subscribe()
+		m.watchPrices()
+		m.watchDepth()
+		m.watchTrades()
+		m.syncTabs()
+		return m, tea.Batch(m.loadChart(), m.loadSparks(), m.loadAbout())
 	case tea.WindowSizeMsg:
         m.Width = msg.Width
 		m.Height = msg.Height
+		m.syncTabs()
         return m, nil
+	case tea.MouseMsg:
+		return m, m.scrollTab(msg)
 	case tea.KeyMsg:
 		switch msg.String(){
 		case "ctrl+c":
-			if m.WSConn != nil{
-				m.WSConn.Close()
-			}
+			m.Close()
 			return m,tea.Quit
 		case "esc":
 			return m,tea.Quit
@@ -261,7 +462,33 @@ func (m model)Update(msg tea.Msg)(tea.Model,tea.Cmd){
 				}
 				return m,cmd
 			}
+		case "r":
+			if m.failed != reqNone {
+				retry := m.failed
+				m.failed = reqNone
+				m.Status = ""
+				return m, m.replay(retry)
+			}
+			if m.anyOffline() {
+				m.reconnectOffline()
+				return m, nil
+			}
+			return m, m.loadAbout()
+		case "1", "2", "3", "4", "5":
+			m.interval = int(msg.String()[0] - '1')
+			return m, m.loadChart()
+		case "b":
+			m.toggleDepth()
+			return m, nil
+		case "tab":
+			return m, m.nextTab(1)
+		case "shift+tab":
+			return m, m.nextTab(-1)
+		case "pgup", "pgdown":
+			return m, m.scrollTab(msg)
 		case "x":
+			return m, m.readNews()
+		case "X":
 			var cmd tea.Cmd
 			cmd = m.openNews() 
 			return m, cmd
@@ -271,21 +498,22 @@ func (m model)Update(msg tea.Msg)(tea.Model,tea.Cmd){
 	return m,nil
 }
 func (m model)View()string{
+	l := m.layout()
 	bg := lipgloss.NewStyle().Width(m.Width).Height(m.Height)
 
 	left := lipgloss.NewStyle().
 	Width((m.Width / 2)-2).
 	AlignHorizontal(lipgloss.Left).
 	// Background(lipgloss.Color("#ff8787")).
-	Foreground(lipgloss.Color(m.secondaryTextColor)).MarginLeft(2).MarginTop(1).
+	Foreground(lipgloss.Color(m.secondaryTextColor)).MarginLeft(2).MarginTop(1).MaxHeight(headerHeight).
 	Render("ALPSTEIN")
 
 	right := lipgloss.NewStyle().
 	Width(m.Width / 2).
 	AlignHorizontal(lipgloss.Right).
 	// Background(lipgloss.Color("#ff8787")).
-	Foreground(lipgloss.Color(m.primaryTextColor)).MarginTop(1).
-	Render(fmt.Sprintf("hey %s! 🫡", m.CurrUser))
+	Foreground(lipgloss.Color(m.primaryTextColor)).MarginTop(1).MaxHeight(headerHeight).
+	Render(m.feedStatus() + m.expiryWarning() + fmt.Sprintf("hey %s! 🫡", m.CurrUser))
 
 	heading := lipgloss.JoinHorizontal(
 		lipgloss.Top,
@@ -293,15 +521,11 @@ func (m model)View()string{
 		right,
 	)
 
-	headingHeight := lipgloss.Height(heading)
-
-	gap := 1 + 4
-	remaining := m.Height - headingHeight - gap
-	sidebar := lipgloss.NewStyle().Width((m.Width * 1 / 4) - 2).Height(remaining).MarginLeft(2).Padding(0).
+	sidebar := lipgloss.NewStyle().Width(l.sidebar).Height(l.height).MarginLeft(2).Padding(0).
 	// Background(lipgloss.Color("#ff8787")).
 	Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color(m.borderColor)).
 	Render(m.renderCryptos())
-	body := lipgloss.NewStyle().Width((m.Width * 3 / 4) - 4).Height(remaining).AlignHorizontal(lipgloss.Center).
+	body := lipgloss.NewStyle().Width(l.body).Height(l.height).AlignHorizontal(lipgloss.Center).
 	// Background(lipgloss.Color("#ff8787")).
 	Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color(m.borderColor)).
 	Render(m.renderCryptoyID())
@@ -324,124 +548,88 @@ footerStinng += "[d] docs "
 footerStinng += "[t] trades "
 footerStinng += "[▲] up "
 footerStinng += "[▼] down "
-footerStinng += "[x] open news "
+footerStinng += "[1-5] interval "
+footerStinng += "[b] book "
+footerStinng += "[tab] analysis/about "
+footerStinng += "[x/X] read/open news "
+footerStinng += "[o] logout "
 footer := lipgloss.NewStyle().Width(m.Width-2).Height(1).Foreground(lipgloss.Color(m.tertiaryTextColor)).
 // Background(lipgloss.Color("#ff8777")).
 MarginLeft(2).AlignHorizontal(lipgloss.Center).Render(footerStinng)
+statusColor := m.secondaryTextColor
+if m.statusIsErr {
+	statusColor = "#fb2c36"
+}
+status := lipgloss.NewStyle().Width(m.Width-2).MaxHeight(1).Foreground(lipgloss.Color(statusColor)).
+MarginLeft(2).AlignHorizontal(lipgloss.Center).Render(m.Status)
 return bg.Render(
 	lipgloss.JoinVertical(
 		lipgloss.Top,
 		heading,
 		"",
 		main,
-		"",
+		status,
 		footer,
 	),
 )
 }
 
 
-func(m *model)FetchLiveCryptos()tea.Cmd{
-	return func() tea.Msg {
-			req,err := http.NewRequest(http.MethodGet,fmt.Sprintf("%s/live-cryptos?limit=8",m.Url),nil)
-	if err != nil{
-
-	}
-	req.Header.Set("Authorization",fmt.Sprintf("Bearer %s",m.Jwt))
-	client := http.Client{
-		 Timeout: 10 * time.Second,
-	}
-	resp,err := client.Do(req)
-	if err != nil{
-		
-	}
-	defer resp.Body.Close()
-
-	httpRes := AllCryptoResponse{}
-	json.NewDecoder(resp.Body).Decode(&httpRes)
+const pageSize = 8
 
-	return LiveCryptosLoaded{
-		Cryptos: httpRes.Data,
-		Metadata: httpRes.Metadata,
-		}
-	}
+func(m *model)FetchLiveCryptos()tea.Cmd{
+	return m.fetchPage(api.FirstPage(pageSize),reqLive)
 }
 func (m *model)FetchNextCryptoBatch()tea.Cmd{
-	return func() tea.Msg {
-		req,err := http.NewRequest(http.MethodGet,fmt.Sprintf("%s/live-cryptos?action=next&limit=8&last_seen=%d|%s",m.Url,m.QueryMetada.LastSeenTime,m.QueryMetada.LastSeenId),nil)
-			if err != nil{
-
-		}
-		req.Header.Set("Authorization",fmt.Sprintf("Bearer %s",m.Jwt))
-		client := http.Client{
-			 Timeout: 10 * time.Second,
-		}
-		resp,err := client.Do(req)
-		if err != nil{
-		
-		}
-		defer resp.Body.Close()
-			httpRes := AllCryptoResponse{}
-		json.NewDecoder(resp.Body).Decode(&httpRes)
-
-		return LiveCryptosLoaded{
-			Cryptos: httpRes.Data,
-			Metadata: httpRes.Metadata,
-		}
-	}
+	return m.fetchPage(api.NextPage(m.QueryMetada,pageSize),reqNext)
 }
 func (m *model)FetchPrevCryptoBatch()tea.Cmd{
+	return m.fetchPage(api.PrevPage(m.QueryMetada,pageSize),reqPrev)
+}
+func (m *model)fetchPage(page api.Page,r request)tea.Cmd{
+	client := m.client
+	// with nothing on screen yet there is no dashboard to fall back to
+	fatal := len(m.Cryptos) == 0 && !m.loaded
 	return func() tea.Msg {
-		req,err := http.NewRequest(http.MethodGet,fmt.Sprintf("%s/live-cryptos?action=prev&limit=8&last_seen=%d|%s",m.Url,m.QueryMetada.FirstSeenTime,m.QueryMetada.FirstSeenId),nil)
-			if err != nil{
-
-		}
-		req.Header.Set("Authorization",fmt.Sprintf("Bearer %s",m.Jwt))
-		client := http.Client{
-			 Timeout: 10 * time.Second,
-		}
-		resp,err := client.Do(req)
-		if err != nil{
-		
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		res, err := client.LiveCryptos(ctx, page)
+		if err != nil {
+			return requestFailed(r, err, fatal)
 		}
-		defer resp.Body.Close()
-		httpRes := AllCryptoResponse{}
-		json.NewDecoder(resp.Body).Decode(&httpRes)
-
-		log.Println(httpRes)
 		return LiveCryptosLoaded{
-			Cryptos: httpRes.Data,
-			Metadata: httpRes.Metadata,
+			Cryptos: res.Data,
+			Metadata: res.Metadata,
 		}
 	}
 }
 func(m *model)fetchCryptoByID()tea.Cmd{
-	return func () tea.Msg {	
-		req,err := http.NewRequest(http.MethodGet,fmt.Sprintf("https://api.alpstein.tech/api/v1/crypto/%s",m.CurrCryptoId),nil)
-		if err != nil{
-			log.Println(err)
-		}
-		req.Header.Set("Authorization",fmt.Sprintf("Bearer %s",m.Jwt))
-		client := http.Client{}
-		res,err := client.Do(req)
-		if err != nil{
-			log.Println(err)
-		}
-		defer res.Body.Close()
-		
-		var cryptoRes SingleCryptoResponse
-		var CryptoMod CryptoModel
-		if err := json.NewDecoder(res.Body).Decode(&cryptoRes); err != nil{
-			log.Println(err)
+	client, id := m.client, m.CurrCryptoId
+	return func () tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		crypto, err := client.CryptoByID(ctx, id)
+		if err != nil {
+			return requestFailed(reqByID, err, false)
 		}
-		json.Unmarshal(cryptoRes.Data[0],&CryptoMod)
-		return SetCurrCrypto(CryptoMod)
+		return SetCurrCrypto(*crypto)
 	}
 }
 
+// requestFailed turns an api error into the message the dashboard reacts
+// to, an expired session asks for a new login and keeps the request around.
+func requestFailed(r request, err error, fatal bool) tea.Msg {
+	if api.IsUnauthorized(err) {
+		return ReauthRequired{retry: r}
+	}
+	log.Println(err)
+	return FetchFailed{Err: err, Fatal: fatal, retry: r}
+}
+
 
 func (m *model)renderCryptos()string{
-	box := lipgloss.NewStyle().Width(m.Width * 1/4 - 2).Padding(1).Foreground(lipgloss.Color(m.secondaryTextColor))
+	l := m.layout()
+	box := lipgloss.NewStyle().Width(l.sidebar).Padding(1).Foreground(lipgloss.Color(m.secondaryTextColor))
 	var s = ""
 	for i,c := range m.Cryptos{
 		if m.Cursor == i {
@@ -450,8 +638,8 @@ func (m *model)renderCryptos()string{
 			box = box.Background(lipgloss.Color(""))
 		}
 
-		symbolStyle := lipgloss.NewStyle().Width((m.Width * 1/4 - 4)/2)
-		symbol := symbolStyle.Render(c.Symbol)
+		symbolStyle := lipgloss.NewStyle().Width(l.card/2)
+		symbol := symbolStyle.Render(c.Symbol + m.renderPnl(c))
 
 		var x = ""
 		xStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#ffb900"))
@@ -459,7 +647,7 @@ func (m *model)renderCryptos()string{
 			x = xStyle.Render("✵")
 		}
 
-		timeStyle := lipgloss.NewStyle().Width((m.Width * 1/4 - 4)/2).AlignHorizontal(lipgloss.Right)
+		timeStyle := lipgloss.NewStyle().Width(l.card/2).AlignHorizontal(lipgloss.Right)
 		time := timeStyle.Render(fmt.Sprintf("%s %s",calcDate(time.Now().UnixMilli(),c.CreatedAt),x))
 
 		top := lipgloss.JoinHorizontal(lipgloss.Top,symbol,time)
@@ -477,64 +665,174 @@ func (m *model)renderCryptos()string{
 		var output strings.Builder
 		output.WriteString(top)
 		output.WriteString("\n")
+		if ticker := m.renderTicker(c); ticker != "" {
+			output.WriteString(ticker)
+			output.WriteString("\n")
+		}
+		if spark := m.renderSpark(c, l.card); spark != "" {
+			output.WriteString(spark)
+			output.WriteString("\n")
+		}
 		output.WriteString(heading)
 		s += box.Render(output.String())
 		s += "\n"
 	}
 	return s
 }
+// renderTicker is the sidebar line with the last price and the 24h change
+// coming off the combined binance stream.
+func (m *model) renderTicker(c api.CryptoModel) string {
+	t, ok := m.tickers[m.priceTopic(c.Symbol)]
+	if !ok {
+		return ""
+	}
+	half := m.layout().card/2
+	price := lipgloss.NewStyle().Width(half).Render(market.FormatPrice(t.Last))
+	changeStyle := lipgloss.NewStyle().Width(half).AlignHorizontal(lipgloss.Right).Foreground(lipgloss.Color("#00c950"))
+	change := t.ChangePercent
+	sign := "▲"
+	if change < 0 {
+		changeStyle = changeStyle.Foreground(lipgloss.Color("#fb2c36"))
+		sign = "▼"
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, price, changeStyle.Render(fmt.Sprintf("%s%.2f%%", sign, math.Abs(change))))
+}
+
+// renderPnl is the short live P&L shown next to a triggered signal in the
+// sidebar, empty until the socket has reported on it.
+func (m *model) renderPnl(c api.CryptoModel) string {
+	res, ok := m.pnl[c.Id]
+	if c.Status != "triggered" || !ok {
+		return ""
+	}
+	if res.Kind == "loss" {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#fb2c36")).Render(fmt.Sprintf(" -%.2f%%", res.Value))
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#00c950")).Render(fmt.Sprintf(" +%.2f%%", res.Value))
+}
+
+func (m *model) renderEmpty(text string) string {
+	return lipgloss.NewStyle().
+		Width(m.layout().body).
+		Height(m.Height / 2).
+		AlignHorizontal(lipgloss.Center).
+		AlignVertical(lipgloss.Bottom).
+		Foreground(lipgloss.Color(m.tertiaryTextColor)).
+		Render(text)
+}
+
 func(m *model)renderCryptoyID()string{
 	if m.CurrCrypto.Id != "" {
+		l := m.layout()
 		var s  = lipgloss.NewStyle().Foreground(lipgloss.Color(m.secondaryTextColor)).AlignHorizontal(lipgloss.Center).MarginTop(0)
+		top, bottom := m.detailParts(l)
 
-		symbolStyle := lipgloss.NewStyle().Width((m.Width * 3 / 4) - 4).AlignHorizontal(lipgloss.Right).PaddingTop(1).PaddingRight(1).Foreground(lipgloss.Color(m.secondaryTextColor))
-		binancePriceStyle := lipgloss.NewStyle().Width((m.Width * 3 / 4) - 4).AlignHorizontal(lipgloss.Right).PaddingRight(1)
-		binanceWSStyle := lipgloss.NewStyle().Width((m.Width * 3 / 4) - 4).AlignHorizontal(lipgloss.Right).PaddingRight(1)
-		negative := json.Number(m.BinanceWSRes.PriceChangePercent) < json.Number(0)
-		sign := ""
-		if !negative {
-			binanceWSStyle = binanceWSStyle.Foreground(lipgloss.Color("#fb2c36"))
-			sign += "▼"
-		}else {
-			binanceWSStyle = binanceWSStyle.Foreground(lipgloss.Color("#00c950"))
-			sign += "▲"
-		}
-		symbol := symbolStyle.Render(m.CurrCrypto.Symbol+"/"+m.CurrCrypto.Name)
-		priceFloat,_ := m.BinanceWSRes.LastPrice.Float64()
-		biancePrice := binancePriceStyle.Render("$"+fmt.Sprintf("%.2f",priceFloat))
-		binanceWS := binanceWSStyle.Render(sign+m.BinanceWSRes.PriceChangePercent.String()+"%")
-		headingStyle := lipgloss.NewStyle()
-		heading := headingStyle.Render(m.CurrCrypto.Heading)
-		
-		agentsOp := "Agent's Opinion \n"
-		agentsOp += m.renderSignals()
-
-		trivia := ""
-		if m.CurrCrypto.Position == "unclear"{
-			trivia += "[s] short position [l] long position"
+		// the tabs get whatever height the rest of the pane leaves over
+		middle := m.middleHeight(l, top, bottom)
+		output := top
+		if middle >= 6 {
+			var tab string
+			switch m.tab {
+			case analysisTab, aboutTab:
+				tab = m.renderScrolling(l.body-4, middle-1)
+			default:
+				tab = m.renderChart(l.chart, middle-1)
+				if l.depth > 0 {
+					tab = lipgloss.JoinHorizontal(lipgloss.Top, tab, " ", m.renderDepth(l.depth, middle-1))
+				}
+			}
+			output += m.renderTabs(l.body-4) + "\n" + tab + "\n\n"
 		}
+		output += bottom
+		return s.Render(output)
+	}else if m.loaded {
+		return m.renderEmpty("no live signals right now, check back soon")
+	}else if m.failed == reqLive {
+		return m.renderEmpty("couldn't load the signals, press [r] to try again")
+	}else {
+		return m.renderEmpty("loading signals...")
+	}
+}
 
-		liveStats := "Live Stats ⚡️\n"
-		liveStats += m.renderLiveStats()
+// middleHeight is what the header and the opinion and stats below leave
+// of the pane for the tabs, top and bottom being those parts rendered.
+func (m *model) middleHeight(l layout, parts ...string) int {
+	if len(parts) == 0 {
+		top, bottom := m.detailParts(l)
+		parts = []string{top, bottom}
+	}
+	used := lipgloss.Height(lipgloss.NewStyle().Width(l.body).Render(strings.Join(parts, "")))
+	return l.height - used - 2
+}
 
-		var output strings.Builder
-		output.WriteString(symbol)
-		output.WriteString("\n")
-		output.WriteString(biancePrice)
-		output.WriteString("\n")
-		output.WriteString(binanceWS)
-		output.WriteString("\n")
-		output.WriteString(heading)
-		output.WriteString("\n\n\n")
-		output.WriteString(agentsOp)
-		output.WriteString("\n")
-		output.WriteString(trivia)
-		output.WriteString("\n\n\n")
-		output.WriteString(liveStats)
-		return s.Render(output.String())
+// detailParts renders the detail pane above the tabs, the coin, its price
+// and the headline, and below them, the agent's plan and the live stats.
+func (m *model) detailParts(l layout) (string, string) {
+	symbolStyle := lipgloss.NewStyle().Width(l.body).AlignHorizontal(lipgloss.Right).PaddingTop(1).PaddingRight(1).Foreground(lipgloss.Color(m.secondaryTextColor))
+	binancePriceStyle := lipgloss.NewStyle().Width(l.body).AlignHorizontal(lipgloss.Right).PaddingRight(1)
+	binanceWSStyle := lipgloss.NewStyle().Width(l.body).AlignHorizontal(lipgloss.Right).PaddingRight(1)
+	negative := m.PriceRes.ChangePercent < 0
+	sign := ""
+	if negative {
+		binanceWSStyle = binanceWSStyle.Foreground(lipgloss.Color("#fb2c36"))
+		sign += "▼"
 	}else {
-		return ""
+		binanceWSStyle = binanceWSStyle.Foreground(lipgloss.Color("#00c950"))
+		sign += "▲"
+	}
+	asOf := ""
+	if at, stale := m.stale(m.priceTopic(m.CurrCrypto.Symbol)); stale {
+		binancePriceStyle = binancePriceStyle.Foreground(lipgloss.Color(m.tertiaryTextColor))
+		binanceWSStyle = binanceWSStyle.Foreground(lipgloss.Color(m.tertiaryTextColor))
+		asOf = "  as of " + at.Format("15:04:05")
+	}
+	symbol := symbolStyle.Render(m.CurrCrypto.Symbol+"/"+m.CurrCrypto.Name)
+	biancePrice := binancePriceStyle.Render(market.FormatPrice(m.PriceRes.Last)+" "+m.market.Route(m.CurrCrypto.Symbol).Quote)
+	binanceWS := binanceWSStyle.Render(sign+fmt.Sprintf("%.2f%%",math.Abs(m.PriceRes.ChangePercent))+asOf)
+	headingStyle := lipgloss.NewStyle()
+	heading := headingStyle.Render(m.CurrCrypto.Heading)
+	tags := m.renderTags(m.CurrCrypto.Tag)
+	
+	agentsOp := "Agent's Opinion \n"
+	agentsOp += m.renderSignals()
+
+	trivia := ""
+	if m.CurrCrypto.Position == "unclear"{
+		trivia += "[s] short position [l] long position"
+	}
+
+	liveStats := "Live Stats ⚡️\n"
+	liveStats += m.renderLiveStats()
+	// the trade tape sits beside the stats when there is room and
+	// under them when there isn't
+	tapeWidth := min(tapeMaxWidth, l.body-lipgloss.Width(liveStats)-4)
+	if tapeWidth >= tapeMinWidth {
+		liveStats = lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().AlignHorizontal(lipgloss.Center).Render(liveStats), "   ", m.renderTape(tapeWidth))
+	} else {
+		liveStats += "\n\n" + m.renderTape(min(tapeMaxWidth, l.body))
+	}
+
+	var top, bottom strings.Builder
+	top.WriteString(symbol)
+	top.WriteString("\n")
+	top.WriteString(biancePrice)
+	top.WriteString("\n")
+	top.WriteString(binanceWS)
+	top.WriteString("\n")
+	top.WriteString(heading)
+	if tags != "" {
+		top.WriteString("\n")
+		top.WriteString(tags)
+		top.WriteString("\n\n")
+	} else {
+		top.WriteString("\n\n\n")
 	}
+	bottom.WriteString(agentsOp)
+	bottom.WriteString("\n")
+	bottom.WriteString(trivia)
+	bottom.WriteString("\n\n\n")
+	bottom.WriteString(liveStats)
+	return top.String(), bottom.String()
 }
 func (m *model) renderSignals() string {
 	box := lipgloss.NewStyle().
@@ -630,6 +928,10 @@ func (m *model)renderLiveStats()string{
 				s += "+" + " "
 			}
 		s += fmt.Sprintf("%.2f%s",m.WSRes.Value,"%")
+		if at, stale := m.stale(m.CurrCryptoId); stale {
+			box = box.Foreground(lipgloss.Color(m.tertiaryTextColor))
+			s += "\nas of " + at.Format("15:04:05")
+		}
 	}else{
 		s += "-"
 	}
@@ -644,86 +946,88 @@ func (m *model)renderLiveStats()string{
 }
 
 
-func (m *model) connectToWS() tea.Cmd {
+// readNews opens the article in the terminal instead of the browser, the
+// reader falls back to the browser itself when it can't make sense of it.
+func (m *model) readNews() tea.Cmd {
+	url := m.CurrCrypto.SourceUrl
 	return func() tea.Msg {
-		dialer := websocket.Dialer{
-			Proxy: http.ProxyFromEnvironment,
-		}
-
-		headers := http.Header{}
-		headers.Set("Origin", "https://alpstein.tech")
-
-		conn, _, err := dialer.Dial("wss://ws.alpstein.tech", headers)
-		if err != nil {
-			log.Println("WS dial error:", err)
-			return nil
+		if url == "" {
+			return StatusMsg{Text: "this signal has no news source", Err: true}
 		}
-
-		// send SUB
-		_ = conn.WriteJSON(WSMsg{
-			Event:   "SUB",
-			Payload: m.CurrCryptoId,
-		})
-
-		return WSConnected{Conn: conn}
+		return ReadNews{URL: url}
 	}
 }
-func (m *model)readFromWSS()tea.Cmd{
+
+func(m *model)openNews()tea.Cmd{
+	url := m.CurrCrypto.SourceUrl
 	return func() tea.Msg {
-		if m.WSConn == nil {
-			return nil
+		if url == "" {
+			return StatusMsg{Text: "this signal has no news source", Err: true}
 		}
-		_, p, err := m.WSConn.ReadMessage()
-		if err != nil {
-			log.Println("WS read error:", err)
-			return nil
+		if err := m.opener.Open(url); err != nil {
+			log.Println("open news:", err)
+			return StatusMsg{Text: err.Error(), Err: true}
 		}
+		return StatusMsg{Text: "opened news in your browser"}
+	}
+}
 
-		var resp WSResp
-		if err := json.Unmarshal(p, &resp); err != nil {
-			return nil
-		}
 
-		return WSRespSingnal(resp)
-	}
+// Close stops both websocket feeds, used on logout so nothing keeps
+// streaming for a session that is gone.
+func (m model) Close() {
+	m.feeds.Stop()
 }
-func (m *model)connectToBinanceWs()tea.Cmd{
-	return func() tea.Msg {
-		conn,_,err := websocket.DefaultDialer.Dial(fmt.Sprintf("wss://stream.binance.com:9443/ws/%susdt@ticker",strings.ToLower(m.CurrCrypto.Symbol)),nil)
-		if err != nil{
-			log.Println("BianceWS err:",err.Error())
-			return nil
-		}
-		log.Println("Connected to binance for: ",strings.ToLower(m.CurrCrypto.Symbol))
-		return BinanceWSConnected{Conn: conn}
+
+func (m *model) replay(r request) tea.Cmd {
+	switch r {
+	case reqLive:
+		return m.FetchLiveCryptos()
+	case reqNext:
+		return m.FetchNextCryptoBatch()
+	case reqPrev:
+		return m.FetchPrevCryptoBatch()
+	case reqByID:
+		return m.fetchCryptoByID()
+	case reqAbout:
+		return m.loadAbout()
 	}
+	return nil
 }
-func(m *model)readFromBinanceWSS()tea.Cmd{
-	return func() tea.Msg {
-		if m.BinanceWSConn == nil{
-			return nil
-		}
-		_,p,err := m.BinanceWSConn.ReadMessage()
-		if err != nil{
-			log.Println("Binance WS read error:", err)
-			return nil
-		}
-		resp := BianceWSResp{}
-		if err := json.Unmarshal(p,&resp); err != nil{
-			log.Println("Binance WS json unmarshall error:", err)
-			return nil
-		}
-		return BinanceWSRespSingnal(resp)
+
+func (m *model) expiryWarning() string {
+	if m.tokenExpiry.IsZero() {
+		return ""
 	}
-}
-func(m *model)openNews()tea.Cmd{
-	return func() tea.Msg {
-	cmd := "open"
-    args := []string{m.CurrCrypto.SourceUrl}
-    return exec.Command(cmd, args...).Start()
+	left := time.Until(m.tokenExpiry)
+	if left > 10*time.Minute {
+		return ""
+	}
+	warn := lipgloss.NewStyle().Foreground(lipgloss.Color("#ffb900"))
+	if left <= 0 {
+		return warn.Render("session expired · ")
 	}
+	return warn.Render(fmt.Sprintf("session expires in %dm · ", int(left.Minutes())+1))
+}
+
+// stale reports when a topic last had data and whether that is longer ago
+// than staleAfter, which means the feed stalled or the socket is half open.
+func (m *model) stale(topic string) (time.Time, bool) {
+	at, ok := m.seen[topic]
+	return at, ok && m.staleAfter > 0 && time.Since(at) > m.staleAfter
 }
 
+func staleTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return StaleTick{}
+	})
+}
+
+func expiryTick() tea.Cmd {
+	return tea.Tick(30*time.Second, func(time.Time) tea.Msg {
+		return ExpiryTick{}
+	})
+}
 
 func debounceCmd(id int, delay time.Duration) tea.Cmd {
 	return tea.Tick(delay, func(time.Time) tea.Msg {
@@ -0,0 +1,148 @@
+package dash
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/whiplashvin/alpstein-tui/api"
+	"github.com/whiplashvin/alpstein-tui/feed"
+	"github.com/whiplashvin/alpstein-tui/market"
+)
+
+// fakeBackend answers LiveCryptos from a script of errors, the rest of
+// api.Backend isn't needed here and panics if called.
+type fakeBackend struct {
+	api.Backend
+	errs  []error
+	calls int
+}
+
+func (f *fakeBackend) LiveCryptos(ctx context.Context, page api.Page) (*api.AllCryptoResponse, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	return &api.AllCryptoResponse{Data: []api.CryptoModel{{Id: "a", Symbol: "BTC"}}}, nil
+}
+
+func newTestDash(t *testing.T, backend api.Backend) *model {
+	t.Helper()
+	router, err := market.NewRouter(market.Options{Provider: "binance", Quote: "USDT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := InitDash("", "me", 170, 60, Options{API: backend, Market: router})
+	t.Cleanup(m.Close)
+	return m
+}
+
+func TestExpiredTokenReplaysRequest(t *testing.T) {
+	backend := &fakeBackend{errs: []error{&api.StatusError{Op: "fetch live cryptos", StatusCode: http.StatusUnauthorized}}}
+	m := newTestDash(t, backend)
+
+	msg := m.FetchLiveCryptos()()
+	reauth, ok := msg.(ReauthRequired)
+	if !ok {
+		t.Fatalf("a 401 gave %T, want ReauthRequired", msg)
+	}
+	if reauth.retry != reqLive {
+		t.Fatalf("retry = %v, want the live fetch", reauth.retry)
+	}
+
+	next, _ := m.Update(reauth)
+	*m = next.(model)
+	if m.pending != reqLive {
+		t.Fatalf("pending = %v, want the live fetch kept for after the login", m.pending)
+	}
+
+	next, cmd := m.Update(TokenRefreshed{Jwt: "fresh"})
+	*m = next.(model)
+	if m.pending != reqNone {
+		t.Errorf("pending = %v after the replay, want none", m.pending)
+	}
+	if m.Jwt != "fresh" {
+		t.Errorf("jwt = %q, want the refreshed one", m.Jwt)
+	}
+	if cmd == nil {
+		t.Fatal("a refreshed token replayed nothing")
+	}
+	replayed := cmd()
+	loaded, ok := replayed.(LiveCryptosLoaded)
+	if !ok {
+		t.Fatalf("the replay gave %T, want LiveCryptosLoaded", replayed)
+	}
+	if len(loaded.Cryptos) != 1 || loaded.Cryptos[0].Id != "a" {
+		t.Errorf("replayed cryptos = %+v", loaded.Cryptos)
+	}
+	if backend.calls != 2 {
+		t.Errorf("backend called %d times, want the failed call and its replay", backend.calls)
+	}
+}
+
+func TestFailedFirstLoadIsFatal(t *testing.T) {
+	backend := &fakeBackend{errs: []error{&api.StatusError{Op: "fetch live cryptos", StatusCode: http.StatusBadGateway}}}
+	m := newTestDash(t, backend)
+
+	failed, ok := m.FetchLiveCryptos()().(FetchFailed)
+	if !ok {
+		t.Fatal("a 502 should fail the fetch")
+	}
+	if !failed.Fatal {
+		t.Error("with nothing loaded yet the failure should be fatal")
+	}
+	next, _ := m.Update(failed)
+	*m = next.(model)
+	if m.failed != reqLive {
+		t.Fatalf("failed = %v, want the live fetch for [r]", m.failed)
+	}
+}
+
+func TestUntaggedFramesFromBeforeASwitchAreDropped(t *testing.T) {
+	m := newTestDash(t, &fakeBackend{})
+	frame := func(gen uint64, res WSResp) {
+		next, _ := m.Update(feed.Frame{Kind: feed.Signal, Gen: gen, Data: res})
+		*m = next.(model)
+	}
+
+	m.CurrCryptoId = "a"
+	m.subscribe()
+	before := m.gens[feed.Signal]
+	frame(before, WSResp{Kind: "profit", Value: 1})
+	if m.WSRes.Value != 1 {
+		t.Fatalf("WSRes = %+v, want the frame for the selected signal", m.WSRes)
+	}
+
+	m.CurrCryptoId = "b"
+	m.WSRes = WSResp{}
+	m.subscribe()
+	if m.gens[feed.Signal] == before {
+		t.Fatal("switching signals kept the generation, frames for a are still taken")
+	}
+	frame(before, WSResp{Kind: "loss", Value: 2})
+	if m.WSRes != (WSResp{}) {
+		t.Errorf("a frame for the previous signal landed on the new one: %+v", m.WSRes)
+	}
+	frame(m.gens[feed.Signal], WSResp{Kind: "profit", Value: 3})
+	if m.WSRes.Value != 3 {
+		t.Errorf("WSRes = %+v, want the new signal's frame", m.WSRes)
+	}
+}
+
+func TestUntaggedFramesKeepThePnlFresh(t *testing.T) {
+	m := newTestDash(t, &fakeBackend{})
+	m.staleAfter = time.Minute
+	m.CurrCryptoId = "a"
+	m.subscribe()
+
+	at := time.Now().Add(-2 * time.Minute)
+	next, _ := m.Update(feed.Frame{Kind: feed.Signal, Gen: m.gens[feed.Signal], Data: WSResp{Kind: "profit", Value: 1}, At: at})
+	*m = next.(model)
+	seen, stale := m.stale(m.CurrCryptoId)
+	if !seen.Equal(at) || !stale {
+		t.Errorf("stale(%q) = %v, %v, want the untagged frame's time and stale", m.CurrCryptoId, seen, stale)
+	}
+}
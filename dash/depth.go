@@ -0,0 +1,146 @@
+package dash
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/whiplashvin/alpstein-tui/feed"
+	"github.com/whiplashvin/alpstein-tui/market"
+)
+
+// depthLevels is how many prices each side of the book shows at most.
+const depthLevels = 10
+
+// nearLevel is how close, as a fraction of price, a book level has to be
+// to the entry or an exit to be picked out.
+const nearLevel = 0.002
+
+// toggleDepth opens or closes the order book panel. The depth stream only
+// runs while the panel is open.
+func (m *model) toggleDepth() {
+	m.showDepth = !m.showDepth
+	m.watchDepth()
+}
+
+// watchDepth points the depth stream at the selected coin, or stops it
+// when the panel is closed.
+func (m *model) watchDepth() {
+	m.book = market.Book{}
+	m.depthErr = ""
+	if !m.showDepth || m.CurrCrypto.Symbol == "" {
+		m.gens[feed.Depth] = m.feeds.Subscribe(feed.Depth, nil)
+		return
+	}
+	route := m.market.Route(m.CurrCrypto.Symbol)
+	depth, ok := route.Venue.(market.Depth)
+	if !ok {
+		m.depthErr = "no order book on " + route.Venue.Name()
+		m.gens[feed.Depth] = m.feeds.Subscribe(feed.Depth, nil)
+		return
+	}
+	m.useVenue(feed.Depth, route.Venue)
+	m.gens[feed.Depth] = m.feeds.Subscribe(feed.Depth, []string{depth.DepthTopic(route.Base, route.Quote)})
+}
+
+// renderDepth draws asks above bids around the spread, each level with a
+// bar for the size resting up to and including it.
+func (m *model) renderDepth(width, height int) string {
+	box := lipgloss.NewStyle().Width(width).Height(height).Foreground(lipgloss.Color(m.secondaryTextColor))
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor))
+	title := "Order Book"
+
+	book := m.book
+	switch {
+	case m.depthErr != "":
+		return box.Render(title + "\n\n" + dim.Render(m.depthErr))
+	case len(book.Bids) == 0 && len(book.Asks) == 0:
+		return box.Render(title + "\n\n" + dim.Render("loading order book..."))
+	}
+
+	// title, column names and the spread take a line each
+	n := min(depthLevels, (height-3)/2)
+	asks := book.Asks[:min(n, len(book.Asks))]
+	bids := book.Bids[:min(n, len(book.Bids))]
+	total := 0.0
+	for _, side := range [][]market.BookLevel{asks, bids} {
+		sum := 0.0
+		for _, l := range side {
+			sum += l.Size
+		}
+		total = max(total, sum)
+	}
+
+	priceW := len("price")
+	for _, l := range slices.Concat(asks, bids) {
+		priceW = max(priceW, len(market.FormatPrice(l.Price)))
+	}
+	sizeW := 9
+	barW := max(width-priceW-sizeW-2, 0)
+
+	levels := m.chartLevels()
+	row := func(l market.BookLevel, cum float64, color string) string {
+		price := lipgloss.NewStyle().Width(priceW).Foreground(lipgloss.Color(color)).Render(market.FormatPrice(l.Price))
+		for _, lv := range levels {
+			if lv.Price > 0 && math.Abs(l.Price-lv.Price) <= lv.Price*nearLevel {
+				price = lipgloss.NewStyle().Width(priceW).Foreground(lipgloss.Color(lv.Color)).Bold(true).Render(market.FormatPrice(l.Price))
+				break
+			}
+		}
+		size := lipgloss.NewStyle().Width(sizeW).AlignHorizontal(lipgloss.Right).Render(formatSize(l.Size))
+		bar := ""
+		if total > 0 {
+			bar = strings.Repeat("█", int(math.Round(cum/total*float64(barW))))
+		}
+		bar = lipgloss.NewStyle().Width(barW).Foreground(lipgloss.Color(color)).Faint(true).Render(bar)
+		return price + " " + size + " " + bar
+	}
+
+	var b strings.Builder
+	b.WriteString(title)
+	b.WriteString("\n")
+	b.WriteString(dim.Render(fmt.Sprintf("%-*s %*s", priceW, "price", sizeW, "size")))
+	b.WriteString("\n")
+	// the cumulative size builds outwards from the spread, the best ask is
+	// drawn last on its side
+	cum := make([]float64, len(asks))
+	sum := 0.0
+	for i, l := range asks {
+		sum += l.Size
+		cum[i] = sum
+	}
+	for i := len(asks) - 1; i >= 0; i-- {
+		b.WriteString(row(asks[i], cum[i], "#fb2c36"))
+		b.WriteString("\n")
+	}
+	spread := book.Spread()
+	spreadText := "spread " + market.FormatPrice(spread)
+	if len(bids) > 0 && bids[0].Price > 0 {
+		spreadText += fmt.Sprintf(" (%.3f%%)", spread/bids[0].Price*100)
+	}
+	b.WriteString(dim.Render(spreadText))
+	b.WriteString("\n")
+	sum = 0
+	for _, l := range bids {
+		sum += l.Size
+		b.WriteString(row(l, sum, "#00c950"))
+		b.WriteString("\n")
+	}
+	return box.Render(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// formatSize keeps sizes to a few significant digits, books for cheap
+// coins run into the millions.
+func formatSize(f float64) string {
+	switch {
+	case f >= 1e6:
+		return fmt.Sprintf("%.2fM", f/1e6)
+	case f >= 1e3:
+		return fmt.Sprintf("%.2fK", f/1e3)
+	case f >= 1:
+		return fmt.Sprintf("%.2f", f)
+	}
+	return fmt.Sprintf("%.4f", f)
+}
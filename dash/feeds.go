@@ -0,0 +1,169 @@
+package dash
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/whiplashvin/alpstein-tui/feed"
+	"github.com/whiplashvin/alpstein-tui/market"
+)
+
+// configureFeeds tells the manager how to reach both sockets. The signal
+// socket is shared by the whole page, the binance one carries every symbol
+// in its url.
+func (m *model) configureFeeds() {
+	headers := http.Header{}
+	if m.wsOrigin != "" {
+		headers.Set("Origin", m.wsOrigin)
+	}
+	wsUrl := m.wsUrl
+	m.feeds.Configure(feed.Signal, feed.Config{
+		URL:         func([]string) string { return wsUrl },
+		Header:      headers,
+		Heartbeat:   m.heartbeat,
+		ReadTimeout: m.readTimeout,
+		Sub:         func(id string) any { return WSMsg{Event: "SUB", Payload: id} },
+		Unsub:       func(id string) any { return WSMsg{Event: "UNSUB", Payload: id} },
+		Decode: func(p []byte) (string, any, error) {
+			var resp WSResp
+			err := json.Unmarshal(p, &resp)
+			return resp.Id, resp, err
+		},
+	})
+	for i, venue := range m.market.Venues() {
+		cfg := venue.Feed()
+		cfg.Heartbeat = m.heartbeat
+		cfg.ReadTimeout = m.readTimeout
+		m.feeds.Configure(priceKind(i), cfg)
+	}
+}
+
+// priceKind is the feed for the i-th venue, every venue in use gets its
+// own socket after the signal one.
+func priceKind(i int) feed.Kind {
+	return feed.Prices + feed.Kind(i)
+}
+
+// subscribe keeps the signal socket subscribed to the selected crypto.
+// Once the backend has shown it names the signal in its frames, every
+// crypto on the visible page and the open trades the trades screen asked
+// for are added, until then their frames couldn't be told apart.
+func (m *model) subscribe() {
+	var ids []string
+	if m.CurrCryptoId != "" {
+		ids = append(ids, m.CurrCryptoId)
+	}
+	if !m.tagged {
+		// the previous signal's frames still in flight look just like the
+		// new one's, only a new socket and generation leaves them behind
+		if m.CurrCryptoId != m.following {
+			m.following = m.CurrCryptoId
+			m.gens[feed.Signal] = m.feeds.Redial(feed.Signal, ids)
+		}
+		return
+	}
+	for _, c := range m.Cryptos {
+		ids = append(ids, c.Id)
+	}
+	ids = append(ids, m.watched...)
+	m.gens[feed.Signal] = m.feeds.Subscribe(feed.Signal, ids)
+}
+
+// watchPrices points every venue at the symbols on the page it prices,
+// the manager redials or resubscribes when the set changes.
+func (m *model) watchPrices() {
+	venues := m.market.Venues()
+	topics := make([][]string, len(venues))
+	add := func(symbol string) {
+		route := m.market.Route(symbol)
+		i := slices.Index(venues, route.Venue)
+		topics[i] = append(topics[i], route.Topic)
+	}
+	for _, c := range m.Cryptos {
+		add(c.Symbol)
+	}
+	if m.CurrCrypto.Symbol != "" {
+		add(m.CurrCrypto.Symbol)
+	}
+	for i := range venues {
+		m.gens[priceKind(i)] = m.feeds.Subscribe(priceKind(i), topics[i])
+	}
+}
+
+// useVenue points a per-coin stream like the chart at the venue the coin
+// is priced on, reconfiguring it only when that changes.
+func (m *model) useVenue(kind feed.Kind, venue market.MarketData) {
+	if m.venues[kind] == venue {
+		return
+	}
+	cfg := venue.Feed()
+	cfg.Heartbeat = m.heartbeat
+	cfg.ReadTimeout = m.readTimeout
+	m.feeds.Configure(kind, cfg)
+	m.venues[kind] = venue
+}
+
+func (m *model) priceTopic(symbol string) string {
+	return m.market.Route(symbol).Topic
+}
+
+// kinds lists the signal feed and then one price feed per venue, in the
+// order the header shows them.
+func (m *model) kinds() []feed.Kind {
+	kinds := []feed.Kind{feed.Signal}
+	for i := range m.market.Venues() {
+		kinds = append(kinds, priceKind(i))
+	}
+	return kinds
+}
+
+func (m *model) kindName(k feed.Kind) string {
+	if k >= feed.Prices {
+		return m.market.Venues()[k-feed.Prices].Name()
+	}
+	return k.String()
+}
+
+// reconnectOffline starts a fresh round for every feed that gave up.
+func (m *model) reconnectOffline() {
+	for _, k := range m.kinds() {
+		if m.feedStates[k].State == feed.Offline {
+			m.gens[k] = m.feeds.Retry(k)
+		}
+	}
+}
+
+func (m *model) anyOffline() bool {
+	for _, st := range m.feedStates {
+		if st.State == feed.Offline {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *model) feedStatus() string {
+	var out string
+	for _, k := range m.kinds() {
+		st := m.feedStates[k]
+		var text, color string
+		switch st.State {
+		case feed.Idle:
+			continue
+		case feed.Connecting:
+			text, color = "connecting", "#ffb900"
+		case feed.Connected:
+			text, color = "connected", "#05df72"
+		case feed.Reconnecting:
+			text, color = fmt.Sprintf("reconnecting (attempt %d)", st.Attempt), "#ffb900"
+		case feed.Offline:
+			text, color = "offline [r]", "#fb2c36"
+		}
+		out += lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("● ") +
+			lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor)).Render(m.kindName(k)+" "+text) + "  "
+	}
+	return out
+}
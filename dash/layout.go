@@ -0,0 +1,33 @@
+package dash
+
+// headerHeight is the "ALPSTEIN" line and the margin above it.
+const headerHeight = 2
+
+// layout is where everything on the dashboard goes at the current
+// terminal size. View and the panes read their sizes from here instead of
+// each redoing the sums.
+type layout struct {
+	sidebar int // width of the signal list inside its border
+	card    int // width of the text on a sidebar card
+	body    int // width of the detail pane inside its border
+	height  int // height of both panes
+	chart   int // width of the chart, less the book when it's open
+	depth   int // width of the order book beside the chart, 0 when closed
+}
+
+func (m *model) layout() layout {
+	l := layout{
+		sidebar: m.Width*1/4 - 2,
+		card:    m.Width*1/4 - 4,
+		body:    m.Width*3/4 - 4,
+		// a blank line under the header, the status and footer lines and
+		// the pane borders
+		height: m.Height - headerHeight - 5,
+	}
+	l.chart = l.body - 4
+	if m.showDepth {
+		l.depth = min(40, l.chart/3)
+		l.chart -= l.depth + 1
+	}
+	return l
+}
@@ -0,0 +1,141 @@
+package dash
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/whiplashvin/alpstein-tui/api"
+	"github.com/whiplashvin/alpstein-tui/chart"
+	"github.com/whiplashvin/alpstein-tui/market"
+)
+
+// sparkPoints is roughly how many bars a sparkline is built from, the
+// interval is picked so the time since the signal was created fits in it.
+const sparkPoints = 60
+
+// spark is the price history behind one sidebar card.
+type spark struct {
+	iv      market.Interval
+	candles []market.Candle
+}
+
+// SparkLoaded carries the history for one signal's sparkline.
+type SparkLoaded struct {
+	id      string
+	iv      market.Interval
+	Candles []market.Candle
+	Err     error
+}
+
+// loadSparks fetches history for the cards on the page that don't have
+// any yet and forgets the ones that scrolled off.
+func (m *model) loadSparks() tea.Cmd {
+	onPage := map[string]bool{}
+	var cmds []tea.Cmd
+	for _, c := range m.Cryptos {
+		onPage[c.Id] = true
+		if _, ok := m.sparks[c.Id]; ok {
+			continue
+		}
+		route := m.market.Route(c.Symbol)
+		charting, ok := route.Venue.(market.Charting)
+		if !ok || c.CreatedAt == 0 {
+			continue
+		}
+		iv, limit := sparkInterval(time.Since(time.UnixMilli(c.CreatedAt)))
+		m.sparks[c.Id] = &spark{iv: iv}
+		id := c.Id
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			candles, err := charting.Candles(ctx, route.Base, route.Quote, iv, limit)
+			return SparkLoaded{id: id, iv: iv, Candles: candles, Err: err}
+		})
+	}
+	for id := range m.sparks {
+		if !onPage[id] {
+			delete(m.sparks, id)
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// sparkInterval is the smallest interval that covers age in sparkPoints
+// bars, and how many bars that takes.
+func sparkInterval(age time.Duration) (market.Interval, int) {
+	for _, iv := range market.Intervals {
+		if n := int(age/iv.Duration()) + 1; n <= sparkPoints {
+			return iv, max(n, 2)
+		}
+	}
+	return market.Intervals[len(market.Intervals)-1], sparkPoints
+}
+
+// foldSpark keeps every card on the symbol moving with the live ticker.
+func (m *model) foldSpark(t market.Ticker) {
+	for _, c := range m.Cryptos {
+		s, ok := m.sparks[c.Id]
+		if !ok || len(s.candles) == 0 || m.priceTopic(c.Symbol) != t.Topic {
+			continue
+		}
+		s.candles = market.Fold(s.candles, t, s.iv)
+	}
+}
+
+func (m *model) sparkLoaded(msg SparkLoaded) {
+	s, ok := m.sparks[msg.id]
+	if !ok || s.iv != msg.iv {
+		return
+	}
+	if msg.Err != nil {
+		log.Println("sparkline:", msg.Err)
+		return
+	}
+	s.candles = msg.Candles
+}
+
+// renderSpark is the card's sparkline, green while price is closer to the
+// take profit than it was at creation and red while it drifts toward the
+// stop loss.
+func (m *model) renderSpark(c api.CryptoModel, width int) string {
+	s, ok := m.sparks[c.Id]
+	if !ok || len(s.candles) == 0 {
+		return ""
+	}
+	closes := make([]float64, len(s.candles))
+	for i, candle := range s.candles {
+		closes[i] = candle.Close
+	}
+	return chart.Spark(closes, width, m.sparkColor(c, closes))
+}
+
+func (m *model) sparkColor(c api.CryptoModel, closes []float64) string {
+	side := c.TriggeredPosition
+	if side == "" {
+		side = c.Position
+	}
+	tp := c.TakeProfit
+	switch side {
+	case "long":
+	case "short":
+		tp = c.ShortCoverProfit
+	default:
+		// an unclear call has no target to head toward
+		return m.tertiaryTextColor
+	}
+	if tp == 0 {
+		return m.tertiaryTextColor
+	}
+	from := c.PriceAtCreation
+	if from == 0 {
+		from = closes[0]
+	}
+	last := closes[len(closes)-1]
+	if math.Abs(last-tp) < math.Abs(from-tp) {
+		return "#00c950"
+	}
+	return "#fb2c36"
+}
@@ -0,0 +1,91 @@
+package dash
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// detailTab is what fills the middle of the detail pane, tab cycles
+// through them.
+type detailTab int
+
+const (
+	chartTab detailTab = iota
+	analysisTab
+	aboutTab
+	detailTabs
+)
+
+var tabNames = [...]string{"Chart", "Analysis", "About"}
+
+// nextTab moves by tabs to the left or right, wrapping around, and loads
+// what the new tab needs.
+func (m *model) nextTab(by int) tea.Cmd {
+	m.tab = (m.tab + detailTab(by) + detailTabs) % detailTabs
+	m.syncTabs()
+	return m.loadAbout()
+}
+
+// syncTabs brings the text tabs up to date with the selected signal and
+// the pane width.
+func (m *model) syncTabs() {
+	m.syncAnalysis()
+	m.syncAbout()
+}
+
+// scrolling is the viewport of the open tab, nil for the chart.
+func (m *model) scrolling() *viewport.Model {
+	switch m.tab {
+	case analysisTab:
+		return &m.analysis
+	case aboutTab:
+		return &m.about
+	}
+	return nil
+}
+
+// scrollTab hands page keys and the mouse wheel to the open tab, at the
+// height the pane leaves it.
+func (m *model) scrollTab(msg tea.Msg) tea.Cmd {
+	vp := m.scrolling()
+	if vp == nil || m.CurrCrypto.Id == "" {
+		return nil
+	}
+	vp.Height = m.middleHeight(m.layout()) - 1
+	var cmd tea.Cmd
+	*vp, cmd = vp.Update(msg)
+	return cmd
+}
+
+// renderScrolling is the open tab's viewport at the height left over,
+// clamped in case the pane shrank under the current scroll position.
+func (m *model) renderScrolling(width, height int) string {
+	vp := *m.scrolling()
+	vp.Width, vp.Height = width, height
+	vp.SetYOffset(vp.YOffset)
+	return vp.View()
+}
+
+// renderTabs is the tab bar over the middle of the pane, the keys that
+// apply on the right.
+func (m *model) renderTabs(width int) string {
+	var tabs []string
+	for i, name := range tabNames {
+		style := lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color(m.tertiaryTextColor))
+		if detailTab(i) == m.tab {
+			style = style.Foreground(lipgloss.Color(m.primaryTextColor)).Underline(true)
+		}
+		tabs = append(tabs, style.Render(name))
+	}
+	bar := lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+	hint := "[tab] switch"
+	if m.scrolling() != nil {
+		hint = "[pgup/pgdn] scroll  " + hint
+	}
+	hint = lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor)).Render(hint)
+	gap := max(width-lipgloss.Width(bar)-lipgloss.Width(hint), 1)
+	return bar + strings.Repeat(" ", gap) + hint
+}
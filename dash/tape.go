@@ -0,0 +1,90 @@
+package dash
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/whiplashvin/alpstein-tui/feed"
+	"github.com/whiplashvin/alpstein-tui/market"
+)
+
+// tapeRows is how many trades the tape shows, the newest on top.
+const tapeRows = 5
+
+// the tape needs about tapeMinWidth for a row and has no use for more
+// than tapeMaxWidth.
+const (
+	tapeMinWidth = 32
+	tapeMaxWidth = 40
+)
+
+// watchTrades swaps the trade stream over to the selected coin. Anything
+// still in flight for the old one carries the old generation and is
+// dropped with it.
+func (m *model) watchTrades() {
+	m.tape = nil
+	m.tapeErr = ""
+	if m.CurrCrypto.Symbol == "" {
+		m.gens[feed.Trades] = m.feeds.Subscribe(feed.Trades, nil)
+		return
+	}
+	route := m.market.Route(m.CurrCrypto.Symbol)
+	tape, ok := route.Venue.(market.Tape)
+	if !ok {
+		m.tapeErr = "no trades on " + route.Venue.Name()
+		m.gens[feed.Trades] = m.feeds.Subscribe(feed.Trades, nil)
+		return
+	}
+	m.useVenue(feed.Trades, route.Venue)
+	m.gens[feed.Trades] = m.feeds.Subscribe(feed.Trades, []string{tape.TradeTopic(route.Base, route.Quote)})
+}
+
+func (m *model) addTrade(t market.Trade) {
+	m.tape = append([]market.Trade{t}, m.tape...)
+	if len(m.tape) > tapeRows {
+		m.tape = m.tape[:tapeRows]
+	}
+}
+
+// large reports whether a trade is worth highlighting, judged on its
+// value in the quote currency so one threshold fits every coin.
+func (m *model) large(t market.Trade) bool {
+	return m.largeTrade > 0 && t.Price*t.Size >= m.largeTrade
+}
+
+// renderTape is the latest trades coloured by the aggressor's side, with
+// the ones over large_trade marked and bold.
+func (m *model) renderTape(width int) string {
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor))
+	title := "Trades"
+	if m.largeTrade > 0 {
+		title += dim.Render(" ● ≥ " + formatSize(m.largeTrade))
+	}
+	lines := []string{title}
+	switch {
+	case m.tapeErr != "":
+		lines = append(lines, dim.Render(m.tapeErr))
+	case len(m.tape) == 0:
+		lines = append(lines, dim.Render("waiting for trades..."))
+	}
+
+	priceW := 0
+	for _, t := range m.tape {
+		priceW = max(priceW, len(market.FormatPrice(t.Price)))
+	}
+	for _, t := range m.tape {
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#fb2c36"))
+		if t.Buy {
+			style = style.Foreground(lipgloss.Color("#00c950"))
+		}
+		mark := " "
+		if m.large(t) {
+			style = style.Bold(true)
+			mark = "●"
+		}
+		row := fmt.Sprintf("%s %s %*s %9s", mark, t.Time.Local().Format("15:04:05"), priceW, market.FormatPrice(t.Price), formatSize(t.Size))
+		lines = append(lines, style.Render(row))
+	}
+	return lipgloss.NewStyle().Width(width).Height(tapeRows + 1).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,221 @@
+// Package docs is the in-app help screen: keys, what every signal field
+// means and where the data comes from, rendered from embedded markdown.
+package docs
+
+import (
+	_ "embed"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+//go:embed docs.md
+var source string
+
+// Close asks to go back to the dashboard.
+type Close struct{}
+
+type model struct {
+	Width              int
+	Height             int
+	primaryTextColor   string
+	secondaryTextColor string
+	tertiaryTextColor  string
+	viewport           viewport.Model
+	search             textinput.Model
+	searching          bool
+	query              string
+	lines              []string
+	matches            []int
+	match              int
+	renderedWidth      int
+}
+
+func InitDocs(width, height int) *model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "search"
+	m := &model{
+		primaryTextColor:   "#a3b3ff",
+		secondaryTextColor: "#c7d8ff",
+		tertiaryTextColor:  "#52525c",
+		viewport:           viewport.New(0, 0),
+		search:             ti,
+	}
+	m.resize(width, height)
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+		return m, nil
+	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		switch msg.String() {
+		case "/":
+			m.searching = true
+			m.search.SetValue(m.query)
+			m.search.CursorEnd()
+			return m, m.search.Focus()
+		case "n":
+			m.jump(m.match + 1)
+			return m, nil
+		case "N":
+			m.jump(m.match - 1)
+			return m, nil
+		case "g", "home":
+			m.viewport.GotoTop()
+			return m, nil
+		case "G", "end":
+			m.viewport.GotoBottom()
+			return m, nil
+		case "esc", "q":
+			if m.query != "" {
+				m.find("")
+				return m, nil
+			}
+			return m, func() tea.Msg { return Close{} }
+		}
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.searching = false
+		m.search.Blur()
+		m.find(m.search.Value())
+		return m, nil
+	case "esc":
+		m.searching = false
+		m.search.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	return m, cmd
+}
+
+// resize lays the viewport out again and re-renders the markdown when the
+// width changed, glamour wraps to it.
+func (m *model) resize(width, height int) {
+	m.Width, m.Height = width, height
+	m.viewport.Width = max(width-4, 0)
+	// title, blank line, the footer and the margin under it
+	m.viewport.Height = max(height-4, 0)
+	if width == m.renderedWidth {
+		return
+	}
+	m.renderedWidth = width
+	m.lines = strings.Split(m.render(max(width-8, 20)), "\n")
+	m.find(m.query)
+}
+
+func (m *model) render(width int) string {
+	r, err := glamour.NewTermRenderer(glamour.WithStandardStyle("dark"), glamour.WithWordWrap(width))
+	if err != nil {
+		log.Println("docs:", err)
+		return source
+	}
+	out, err := r.Render(source)
+	if err != nil {
+		log.Println("docs:", err)
+		return source
+	}
+	return strings.Trim(out, "\n")
+}
+
+// find collects the lines containing query, ignoring case and styling,
+// and scrolls to the first.
+func (m *model) find(query string) {
+	m.query = strings.TrimSpace(query)
+	m.matches = nil
+	if m.query != "" {
+		q := strings.ToLower(m.query)
+		for i, line := range m.lines {
+			if strings.Contains(strings.ToLower(ansi.Strip(line)), q) {
+				m.matches = append(m.matches, i)
+			}
+		}
+	}
+	m.match = 0
+	m.viewport.SetContent(m.content())
+	if len(m.matches) > 0 {
+		m.jump(0)
+	}
+}
+
+// jump scrolls to the i-th match, wrapping around either end.
+func (m *model) jump(i int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.match = (i%len(m.matches) + len(m.matches)) % len(m.matches)
+	m.viewport.SetContent(m.content())
+	// keep a little context above the match
+	m.viewport.SetYOffset(m.matches[m.match] - 2)
+}
+
+// content is the rendered docs with a gutter marking the lines that
+// match, the current one brighter than the rest.
+func (m *model) content() string {
+	mark := map[int]string{}
+	for i, line := range m.matches {
+		color := m.tertiaryTextColor
+		if i == m.match {
+			color = m.primaryTextColor
+		}
+		mark[line] = lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("▌")
+	}
+	var b strings.Builder
+	for i, line := range m.lines {
+		if g, ok := mark[i]; ok {
+			b.WriteString(g)
+		} else {
+			b.WriteString(" ")
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (m model) View() string {
+	title := lipgloss.NewStyle().Foreground(lipgloss.Color(m.secondaryTextColor)).MarginLeft(2).MarginTop(1).
+		Render("ALPSTEIN · docs")
+
+	var footer string
+	switch {
+	case m.searching:
+		footer = m.search.View()
+	case m.query != "" && len(m.matches) == 0:
+		footer = fmt.Sprintf("no match for %q  [/] search [esc] clear", m.query)
+	case m.query != "":
+		footer = fmt.Sprintf("%q %d/%d  [n] next [N] prev [esc] clear", m.query, m.match+1, len(m.matches))
+	default:
+		footer = fmt.Sprintf("%3.f%%  [▲/▼] scroll [/] search [esc] back", m.viewport.ScrollPercent()*100)
+	}
+	footer = lipgloss.NewStyle().Width(m.Width - 2).MarginLeft(2).AlignHorizontal(lipgloss.Center).
+		Foreground(lipgloss.Color(m.tertiaryTextColor)).Render(footer)
+
+	body := lipgloss.NewStyle().MarginLeft(2).Render(m.viewport.View())
+	return lipgloss.JoinVertical(lipgloss.Left, title, body, footer)
+}
@@ -0,0 +1,88 @@
+// Package feed keeps the dashboard's websockets alive outside the Bubble Tea
+// model. Every socket runs in its own goroutine, reconnects on its own and
+// hands frames to the program with Send, tagged so the model can tell a
+// frame from a connection it already moved away from.
+package feed
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Skip is returned by Decode for frames that carry nothing worth
+// delivering, like subscription acks.
+var Skip = errors.New("feed: skip frame")
+
+// Kind names one of the live streams.
+type Kind int
+
+const (
+	Signal Kind = iota
+	Chart
+	Depth
+	Trades
+	// Prices is the first price stream, further venues follow it.
+	Prices
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Signal:
+		return "p&l"
+	case Chart:
+		return "chart"
+	case Depth:
+		return "depth"
+	case Trades:
+		return "trades"
+	}
+	return "price"
+}
+
+type State int
+
+const (
+	Idle State = iota
+	Connecting
+	Connected
+	Reconnecting
+	Offline
+)
+
+// Config describes how to talk to one stream.
+type Config struct {
+	// URL builds the address to dial for the given topics.
+	URL    func(topics []string) string
+	Header http.Header
+	// Sub and Unsub build the messages that change topics on an open
+	// socket. When they are nil the topics live in the url and changing
+	// them means a new connection.
+	Sub   func(topic string) any
+	Unsub func(topic string) any
+	// Heartbeat is how often to ping, ReadTimeout how long the socket may
+	// stay silent before it counts as dead. Zero turns either off.
+	Heartbeat   time.Duration
+	ReadTimeout time.Duration
+	// Decode turns a frame into the message handed to the program and
+	// the topic it belongs to, "" when it can't tell.
+	Decode func(p []byte) (topic string, msg any, err error)
+}
+
+// Frame is one decoded message off a socket.
+type Frame struct {
+	Kind  Kind
+	Gen   uint64
+	Topic string
+	Data  any
+	At    time.Time
+}
+
+// StatusMsg reports a change in a stream's connection.
+type StatusMsg struct {
+	Kind    Kind
+	Gen     uint64
+	State   State
+	Attempt int
+	Err     error
+}
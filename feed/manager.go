@@ -0,0 +1,371 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	reconnectBase = time.Second
+	reconnectMax  = 30 * time.Second
+	maxReconnects = 8
+	writeWait     = 10 * time.Second
+)
+
+// Manager owns every live socket. The model only ever calls Subscribe,
+// Retry and Stop, all of which return straight away, and everything that
+// comes off the wire arrives later as a Frame or StatusMsg.
+type Manager struct {
+	mu      sync.Mutex
+	send    func(tea.Msg)
+	streams map[Kind]*stream
+	gen     uint64
+	wg      sync.WaitGroup
+}
+
+type stream struct {
+	cfg     Config
+	gen     uint64
+	cancel  context.CancelFunc
+	running bool
+	topics  []string
+	poke    chan struct{}
+}
+
+func New() *Manager {
+	return &Manager{streams: map[Kind]*stream{}}
+}
+
+// Attach sets where messages go, normally the running program's Send.
+// Nothing is delivered before it is called.
+func (m *Manager) Attach(send func(tea.Msg)) {
+	m.mu.Lock()
+	m.send = send
+	m.mu.Unlock()
+}
+
+// Configure sets how to reach a stream, stopping whatever was running
+// under that kind before.
+func (m *Manager) Configure(kind Kind, cfg Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s := m.streams[kind]; s != nil && s.cancel != nil {
+		s.cancel()
+	}
+	m.streams[kind] = &stream{cfg: cfg}
+}
+
+// Subscribe points a stream at topics and returns the generation its
+// messages will carry. Streams with Sub/Unsub keep their socket and just
+// change subscriptions, the rest redial under a new generation.
+func (m *Manager) Subscribe(kind Kind, topics []string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.streams[kind]
+	if s == nil {
+		return 0
+	}
+	topics = slices.Clone(topics)
+	slices.Sort(topics)
+	topics = slices.Compact(topics)
+	if s.running {
+		if s.cfg.Sub != nil {
+			s.topics = topics
+			s.wake()
+			return s.gen
+		}
+		if slices.Equal(topics, s.topics) {
+			return s.gen
+		}
+		s.cancel()
+	}
+	s.topics = topics
+	if len(topics) == 0 {
+		s.running = false
+		return s.gen
+	}
+	m.start(kind, s)
+	return s.gen
+}
+
+// Redial points a stream at topics on a fresh socket under a new
+// generation, even one with Sub/Unsub. Frames that don't say which topic
+// they belong to can't be filtered after a change of subscriptions, the
+// new generation drops the ones still in flight.
+func (m *Manager) Redial(kind Kind, topics []string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.streams[kind]
+	if s == nil {
+		return 0
+	}
+	if s.running {
+		s.cancel()
+		s.running = false
+	}
+	s.topics = slices.Compact(slices.Sorted(slices.Values(topics)))
+	if len(s.topics) > 0 {
+		m.start(kind, s)
+	}
+	return s.gen
+}
+
+// Retry starts a stream again once it has given up reconnecting.
+func (m *Manager) Retry(kind Kind) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.streams[kind]
+	if s == nil {
+		return 0
+	}
+	if !s.running && len(s.topics) > 0 {
+		m.start(kind, s)
+	}
+	return s.gen
+}
+
+// Stop cancels every stream without waiting for them, so it is safe to
+// call from inside Update. Anything still in flight carries an old
+// generation and gets dropped by the model.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.streams {
+		if s.cancel != nil {
+			s.cancel()
+		}
+		s.running = false
+		s.topics = nil
+	}
+}
+
+// Close stops everything and waits for the goroutines to finish. Call it
+// once the program has exited, Send would block inside Update.
+func (m *Manager) Close() {
+	m.Stop()
+	m.wg.Wait()
+}
+
+// start must be called with m.mu held.
+func (m *Manager) start(kind Kind, s *stream) {
+	m.gen++
+	s.gen = m.gen
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.running = true
+	s.poke = make(chan struct{}, 1)
+	m.wg.Add(1)
+	go m.run(ctx, kind, s, s.gen, s.poke)
+}
+
+func (s *stream) wake() {
+	select {
+	case s.poke <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) emit(ctx context.Context, msg tea.Msg) {
+	// a cancelled stream has been replaced, its frames are stale
+	if ctx.Err() != nil {
+		return
+	}
+	m.mu.Lock()
+	send := m.send
+	m.mu.Unlock()
+	if send != nil {
+		send(msg)
+	}
+}
+
+// run keeps one stream connected until ctx is cancelled or it runs out of
+// reconnect attempts.
+func (m *Manager) run(ctx context.Context, kind Kind, s *stream, gen uint64, poke chan struct{}) {
+	defer m.wg.Done()
+	defer func() {
+		m.mu.Lock()
+		if s.gen == gen {
+			s.running = false
+		}
+		m.mu.Unlock()
+	}()
+
+	status := func(state State, attempt int, err error) {
+		m.emit(ctx, StatusMsg{Kind: kind, Gen: gen, State: state, Attempt: attempt, Err: err})
+	}
+	attempt := 0
+	status(Connecting, 0, nil)
+	for {
+		err := m.serve(ctx, kind, s, gen, poke, func() {
+			attempt = 0
+			status(Connected, 0, nil)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("%s feed: %v", kind, err)
+		if attempt >= maxReconnects {
+			status(Offline, attempt, err)
+			return
+		}
+		attempt++
+		status(Reconnecting, attempt, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff(attempt - 1)):
+		}
+	}
+}
+
+// serve dials once and reads until the socket breaks.
+func (m *Manager) serve(ctx context.Context, kind Kind, s *stream, gen uint64, poke chan struct{}, connected func()) error {
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 10 * time.Second,
+	}
+	conn, _, err := dialer.DialContext(ctx, s.cfg.URL(m.topics(s)), s.cfg.Header)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	connected()
+
+	done := make(chan struct{})
+	defer close(done)
+	// closing the socket is the only way to unblock a pending read
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	if s.cfg.Sub != nil {
+		go m.write(conn, s, poke, done)
+	}
+	heartbeat(conn, s.cfg, done)
+
+	for {
+		_, p, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		extend(conn, s.cfg.ReadTimeout)
+		topic, data, err := s.cfg.Decode(p)
+		if errors.Is(err, Skip) {
+			continue
+		}
+		if err != nil {
+			log.Printf("%s feed: decoding frame: %v", kind, err)
+			continue
+		}
+		// frames can still arrive for a topic we just unsubscribed from
+		if s.cfg.Sub != nil && topic != "" && !slices.Contains(m.topics(s), topic) {
+			continue
+		}
+		m.emit(ctx, Frame{Kind: kind, Gen: gen, Topic: topic, Data: data, At: time.Now()})
+	}
+}
+
+// heartbeat arms the read deadline and keeps it moving on pings and pongs.
+// A half open socket then fails its read instead of hanging on forever.
+func heartbeat(conn *websocket.Conn, cfg Config, done chan struct{}) {
+	extend(conn, cfg.ReadTimeout)
+	conn.SetPongHandler(func(string) error {
+		extend(conn, cfg.ReadTimeout)
+		return nil
+	})
+	conn.SetPingHandler(func(data string) error {
+		extend(conn, cfg.ReadTimeout)
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(writeWait))
+		if errors.Is(err, websocket.ErrCloseSent) {
+			return nil
+		}
+		return err
+	})
+	if cfg.Heartbeat <= 0 {
+		return
+	}
+	go func() {
+		t := time.NewTicker(cfg.Heartbeat)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				// WriteControl is safe next to the writer goroutine
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+}
+
+func extend(conn *websocket.Conn, timeout time.Duration) {
+	if timeout > 0 {
+		conn.SetReadDeadline(time.Now().Add(timeout))
+	}
+}
+
+// write is the only goroutine that writes to conn. Each poke diffs the
+// wanted topics against what this socket has been sent so far.
+func (m *Manager) write(conn *websocket.Conn, s *stream, poke chan struct{}, done chan struct{}) {
+	sent := map[string]bool{}
+	for {
+		want := m.topics(s)
+		for topic := range sent {
+			if slices.Contains(want, topic) {
+				continue
+			}
+			if err := conn.WriteJSON(s.cfg.Unsub(topic)); err != nil {
+				conn.Close()
+				return
+			}
+			delete(sent, topic)
+		}
+		for _, topic := range want {
+			if sent[topic] {
+				continue
+			}
+			if err := conn.WriteJSON(s.cfg.Sub(topic)); err != nil {
+				conn.Close()
+				return
+			}
+			sent[topic] = true
+		}
+		select {
+		case <-done:
+			return
+		case <-poke:
+		}
+	}
+}
+
+func (m *Manager) topics(s *stream) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(s.topics)
+}
+
+// backoff doubles from reconnectBase up to reconnectMax and picks a random
+// point in the upper half so a fleet of clients doesn't redial in lockstep.
+func backoff(attempt int) time.Duration {
+	d := reconnectBase << min(attempt, 5)
+	if d > reconnectMax {
+		d = reconnectMax
+	}
+	return d/2 + rand.N(d/2+1)
+}
@@ -1,34 +1,48 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/whiplashvin/alpstein-tui/api"
+	"github.com/whiplashvin/alpstein-tui/auth"
+	"github.com/whiplashvin/alpstein-tui/browser"
+	"github.com/whiplashvin/alpstein-tui/config"
+	"github.com/whiplashvin/alpstein-tui/creds"
 	"github.com/whiplashvin/alpstein-tui/loading"
+	"github.com/whiplashvin/alpstein-tui/market"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 
 	err "github.com/whiplashvin/alpstein-tui/error"
 
 	"github.com/whiplashvin/alpstein-tui/dash"
+	"github.com/whiplashvin/alpstein-tui/docs"
+	"github.com/whiplashvin/alpstein-tui/feed"
+	"github.com/whiplashvin/alpstein-tui/reader"
+	"github.com/whiplashvin/alpstein-tui/trades"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 const version = "0.7"
 
+const (
+	loginPaste    = "paste"
+	loginLoopback = "loopback"
+	loginDevice   = "device"
+)
+
 type Screen int
 
 const  (
@@ -36,34 +50,43 @@ const  (
 	DashScreen
 	LoadingScreen
 	ErrorScreen
+	DocsScreen
+	TradesScreen
+	ReaderScreen
 
 )
 type userMsg string
 type ErrorMessage string
 type startAuthMsg struct{}
-
-type jwtResultMsg struct {
-	jwt string
-	err string
+type tokenRejectedMsg struct{}
+type signinURLMsg struct {
+	url     string
+	openErr error
 }
-
-type HttpResponse  struct {
-	Message string 		`json:"message,omitempty"`
-	Data 	string	`json:"data,omitempty"`
+type loopbackStartedMsg struct {
+	lb      *auth.Loopback
+	url     string
+	openErr error
 }
-
-
-
-type UserResType struct{
-	Email string `json:"email"`
-	FirstName string `json:"firstName"`
-	LastName string `json:"lastName"`
-	ImageUrl string `json:"imageUrl"`
+type deviceStartedMsg struct {
+	code *auth.DeviceCode
+	qr   string
+}
+// loopbackFailedMsg means lb gave up waiting for the browser, its listener
+// is already shut down.
+type loopbackFailedMsg struct {
+	lb  *auth.Loopback
+	err error
+}
+type loopbackCodeMsg struct {
+	code        string
+	verifier    string
+	redirectURI string
 }
 
-type User struct {
-	Message string   `json:"message"`
-	Data    UserResType `json:"data"`
+type jwtResultMsg struct {
+	jwt string
+	err string
 }
 
 type model struct{
@@ -79,12 +102,29 @@ type model struct{
 	dashboard tea.Model
 	loader tea.Model
 	errorModel tea.Model
+	docs tea.Model
+	trades tea.Model
+	reader tea.Model
 	bgColor string
 	primaryTextColor string
 	secondaryTextColor string
+	store *creds.Store
+	loginMode string
+	signinURL string
+	openErr string
+	opener browser.Opener
+	loopback *auth.Loopback
+	device *auth.DeviceCode
+	deviceQR string
+	reauth bool
+	reauthErr string
+	profile string
+	dashOpts dash.Options
+	client api.Backend
+	feeds *feed.Manager
 }
 
-func initModel(u,c,cb string)*model{
+func initModel(cfg config.Config,store *creds.Store,router *market.Router)*model{
 	loaderMod := loading.InitLoading()
 	errMod := err.InitError()
 	ti := textinput.New()
@@ -96,65 +136,168 @@ func initModel(u,c,cb string)*model{
 	ti.Width = 40
 	ti.PlaceholderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#555"))
 	ti.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#a3b3ff")) 
-	return &model{
-		BE_URL: u,
-		OAUTH_CLIENT: c,
-		OAUTH_CB: cb,
+	opener := browser.Opener{Command: cfg.Browser}
+	client := api.New(cfg.BackendURL)
+	feeds := feed.New()
+	m := &model{
+		BE_URL: cfg.BackendURL,
+		OAUTH_CLIENT: cfg.OAuthClient,
+		OAUTH_CB: cfg.OAuthCallback,
 		t: ti,
 		errorModel:  errMod,
 		loader: loaderMod,
 		// bgColor: "#18181a",
 		primaryTextColor: "#a3b3ff",
 		secondaryTextColor: "#c7d8ff",
+		store: store,
+		loginMode: cfg.Login,
+		opener: opener,
+		client: client,
+		feeds: feeds,
+		dashOpts: dash.Options{
+			API: client,
+			WSUrl: cfg.WSURL,
+			WSOrigin: cfg.WSOrigin,
+			Market: router,
+			Opener: opener,
+			Feeds: feeds,
+			Heartbeat: cfg.Heartbeat,
+			ReadTimeout: cfg.ReadTimeout,
+			StaleAfter: cfg.StaleAfter,
+			LargeTrade: cfg.LargeTrade,
+		},
+		profile: creds.DefaultProfile,
+	}
+	if store != nil {
+		m.profile = store.Profile
+		jwt, err := store.Load()
+		if err != nil {
+			log.Println("stored credentials:", err)
+		}else if creds.Expired(jwt) {
+			log.Println("stored credentials: token expired")
+		}else{
+			m.jwt = jwt
+			m.client.SetToken(jwt)
+			m.Screen = LoadingScreen
+		}
 	}
+	return m
 }
 func(m model)Init()tea.Cmd{
 	log.Println("Program started")
-	m.generateSigninURL()
-	return tea.Batch(textinput.Blink,m.loader.Init())
+	if m.jwt != "" {
+		return tea.Batch(m.loader.Init(),m.getUserDetails(m.jwt))
+	}
+	return tea.Batch(textinput.Blink,m.loader.Init(),m.signIn())
 }
 func(m model)Update(msg tea.Msg)(tea.Model,tea.Cmd){
 	switch msg := msg.(type){
-		case dash.PositionDisplayed:
-			var cmd tea.Cmd
-			m.dashboard,cmd = m.dashboard.Update(msg)
-			return m,cmd
-		case dash.WSConnected:
-			var cmd tea.Cmd
-			m.dashboard,cmd = m.dashboard.Update(msg)
-			return m,cmd
-		case dash.WSRespSingnal:
-			var cmd tea.Cmd
-			m.dashboard,cmd = m.dashboard.Update(msg)
-			return m,cmd
-		case dash.BinanceWSConnected:
-			var cmd tea.Cmd
-			m.dashboard,cmd = m.dashboard.Update(msg)
-			return m,cmd
-		case dash.BinanceWSRespSingnal:
+		case feed.Frame, feed.StatusMsg:
+			// the feeds of a dashboard closed on logout still report in
+			if m.dashboard == nil {
+				return m, nil
+			}
 			var cmd tea.Cmd
 			m.dashboard,cmd = m.dashboard.Update(msg)
+			if m.Screen == TradesScreen {
+				// open trades share the signal socket with the dashboard
+				m.trades,_ = m.trades.Update(msg)
+			}
 			return m,cmd
-		case dash.DebounceFetch:
+		case dash.FetchFailed:
 			var cmd tea.Cmd
-			m.dashboard,cmd = m.dashboard.Update(msg)
+			if m.dashboard != nil {
+				// the dashboard keeps the request, so [r] works after esc
+				m.dashboard,cmd = m.dashboard.Update(msg)
+			}
+			if msg.Fatal {
+				return m, func() tea.Msg {
+					return err.ErrSignal{Msg: msg.Error()}
+				}
+			}
 			return m,cmd
-		case dash.SetCryptoId:
+		case dash.PositionDisplayed, dash.DebounceFetch, dash.SetCryptoId, dash.SetCurrCrypto, dash.StatusMsg, dash.ClearStatus,
+			dash.StaleTick, dash.ExpiryTick, dash.CandlesLoaded, dash.SparkLoaded, dash.WatchSignals, dash.AboutLoaded:
+			// ticks, timers and fetches of a dashboard that was closed on
+			// logout, e.g. a status clearing itself or a debounced move
+			if m.dashboard == nil {
+				return m, nil
+			}
 			var cmd tea.Cmd
 			m.dashboard,cmd = m.dashboard.Update(msg)
 			return m,cmd
-		case dash.SetCurrCrypto:	
+		case dash.ReauthRequired:
+			// a request that was in flight when the session logged out
+			if m.dashboard == nil {
+				return m, nil
+			}
 			var cmd tea.Cmd
 			m.dashboard,cmd = m.dashboard.Update(msg)
-			return m,cmd
+			if m.reauth {
+				return m,cmd
+			}
+			m.reauth = true
+			m.reauthErr = ""
+			m.device = nil
+			m.t.Reset()
+			return m,tea.Batch(cmd,textinput.Blink,m.signIn())
 		case jwtResultMsg:
 			if msg.err != "" {
 				return m, m.handleError(msg.err)
 			}
 			m.jwt = msg.jwt
+			m.client.SetToken(msg.jwt)
+			m.device = nil
+			if m.store != nil {
+				if err := m.store.Save(msg.jwt); err != nil {
+					log.Println("saving credentials:", err)
+				}
+			}
+			if m.reauth {
+				m.reauth = false
+				var cmd tea.Cmd
+				m.dashboard,cmd = m.dashboard.Update(dash.TokenRefreshed{Jwt: msg.jwt})
+				return m, cmd
+			}
+			m.Screen = LoadingScreen
 		return m, m.getUserDetails(msg.jwt)
+		case tokenRejectedMsg:
+			if m.store != nil {
+				if err := m.store.Clear(); err != nil {
+					log.Println("clearing credentials:", err)
+				}
+			}
+			m.jwt = ""
+			m.Screen = AuthScreen
+			return m, tea.Batch(textinput.Blink,m.signIn())
 		case startAuthMsg:
     		return m, m.handleAuth()
+		case signinURLMsg:
+			m.signinURL = msg.url
+			m.setOpenErr(msg.openErr)
+			return m, nil
+		case loopbackStartedMsg:
+			m.loopback = msg.lb
+			m.signinURL = msg.url
+			m.setOpenErr(msg.openErr)
+			return m, waitForLoopback(msg.lb)
+		case deviceStartedMsg:
+			m.device = msg.code
+			m.deviceQR = msg.qr
+			return m, m.pollDevice(msg.code)
+		case loopbackFailedMsg:
+			// a login abandoned on logout has nothing left to report
+			if msg.lb != m.loopback {
+				return m, nil
+			}
+			m.loopback = nil
+			return m, func() tea.Msg { return ErrorMessage(msg.err.Error()) }
+		case loopbackCodeMsg:
+			m.loopback = nil
+			if !m.reauth {
+				m.Screen = LoadingScreen
+			}
+			return m, m.exchangeCode(msg)
 		case err.ErrSignal:
 				m.Screen = ErrorScreen
 				var cmd tea.Cmd
@@ -175,35 +318,153 @@ func(m model)Update(msg tea.Msg)(tea.Model,tea.Cmd){
 			m.height = msg.Height
 			var cmd tea.Cmd
 			var cmd1 tea.Cmd
-			// var cmd2 tea.Cmd
+			var cmd2 tea.Cmd
 			m.errorModel,cmd = m.errorModel.Update(msg)
 			m.loader,cmd1 = m.loader.Update(msg)
-			// m.dashboard,cmd2 = m.dashboard.Update(msg)
-        	return m, tea.Batch(cmd,cmd1)
+			if m.dashboard != nil {
+				m.dashboard,cmd2 = m.dashboard.Update(msg)
+			}
+			if m.docs != nil {
+				m.docs,_ = m.docs.Update(msg)
+			}
+			if m.trades != nil {
+				m.trades,_ = m.trades.Update(msg)
+			}
+			if m.reader != nil {
+				m.reader,_ = m.reader.Update(msg)
+			}
+        	return m, tea.Batch(cmd,cmd1,cmd2)
 		 case userMsg:
         	m.CurrUser = string(msg)
-        	dash := dash.InitDash(m.jwt,m.BE_URL,m.CurrUser,m.width,m.height)
+        	dash := dash.InitDash(m.jwt,m.CurrUser,m.width,m.height,m.dashOpts)
         	m.dashboard = dash
 			m.Screen = DashScreen
 			return m, m.dashboard.Init()
 		case ErrorMessage:
+			m.device = nil
+			if m.reauth {
+				m.reauthErr = string(msg)
+				return m, nil
+			}
 			cmd := func ()tea.Msg  {
 				return err.ErrSignal{Msg: string(msg)}
 			}
 			return m, cmd
 
+		case docs.Close:
+			m.Screen = DashScreen
+			return m, nil
+		case trades.Loaded:
+			if m.Screen != TradesScreen {
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.trades, cmd = m.trades.Update(msg)
+			return m, cmd
+		case trades.Close:
+			m.Screen = DashScreen
+			m.trades = nil
+			return m, nil
+		case dash.ReadNews:
+			if m.Screen != DashScreen {
+				return m, nil
+			}
+			m.reader = reader.InitReader(msg.URL, m.opener, m.width, m.height)
+			m.Screen = ReaderScreen
+			return m, m.reader.Init()
+		case reader.Loaded, reader.Opened:
+			if m.Screen != ReaderScreen {
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.reader, cmd = m.reader.Update(msg)
+			return m, cmd
+		case reader.Close:
+			m.Screen = DashScreen
+			m.reader = nil
+			return m, nil
+		case tea.MouseMsg:
+			if m.Screen == DocsScreen {
+				var cmd tea.Cmd
+				m.docs, cmd = m.docs.Update(msg)
+				return m, cmd
+			}
+			if m.Screen == ReaderScreen {
+				var cmd tea.Cmd
+				m.reader, cmd = m.reader.Update(msg)
+				return m, cmd
+			}
+			// the wheel scrolls the analysis tab
+			if m.Screen == DashScreen && !m.reauth {
+				var cmd tea.Cmd
+				m.dashboard, cmd = m.dashboard.Update(msg)
+				return m, cmd
+			}
 		case tea.KeyMsg:
+		if m.reauth && m.Screen == DashScreen {
+			return m.updateReauth(msg)
+		}
+		// the docs take every key, the search box needs letters
+		if m.Screen == DocsScreen && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			m.docs, cmd = m.docs.Update(msg)
+			return m, cmd
+		}
+		if m.Screen == TradesScreen && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			m.trades, cmd = m.trades.Update(msg)
+			return m, cmd
+		}
+		if m.Screen == ReaderScreen && msg.String() != "ctrl+c" {
+			var cmd tea.Cmd
+			m.reader, cmd = m.reader.Update(msg)
+			return m, cmd
+		}
 		switch msg.String(){
 		case "ctrl+c":
 			return m,tea.Quit
+		case "o":
+			if m.Screen == DashScreen {
+				return m.logout()
+			}
+		case "d":
+			if m.Screen == DashScreen {
+				if m.docs == nil {
+					m.docs = docs.InitDocs(m.width, m.height)
+				}
+				m.Screen = DocsScreen
+				return m, nil
+			}
+		case "t":
+			if m.Screen == DashScreen {
+				m.trades = trades.InitTrades(m.client, m.width, m.height)
+				m.Screen = TradesScreen
+				return m, m.trades.Init()
+			}
 		case "esc":
 			switch m.Screen {
 			case ErrorScreen:
 				m.Screen = AuthScreen
+				if m.dashboard != nil {
+					m.Screen = DashScreen
+				}
 			case DashScreen:
 				m.Screen = AuthScreen	
 			}
 		case "enter":
+			if m.Screen == AuthScreen && m.loginMode == loginLoopback {
+				if m.loopback == nil {
+					return m, m.signIn()
+				}
+				m.setOpenErr(m.opener.Open(m.signinURL))
+				return m, nil
+			}
+			if m.Screen == AuthScreen && m.loginMode == loginDevice {
+				if m.device == nil {
+					return m, m.signIn()
+				}
+				return m, nil
+			}
     		if m.Screen == AuthScreen {
 				m.Screen = LoadingScreen
 				return m, 
@@ -247,7 +508,19 @@ func(m model)Update(msg tea.Msg)(tea.Model,tea.Cmd){
 				m.dashboard, cmd = m.dashboard.Update(msg)
 				return m, cmd
 			}
-		case "x":
+		case "r":
+			if m.Screen == DashScreen{
+				var cmd tea.Cmd
+				m.dashboard, cmd = m.dashboard.Update(msg)
+				return m, cmd
+			}
+		case "x", "X":
+			if m.Screen == DashScreen{
+				var cmd tea.Cmd
+				m.dashboard, cmd = m.dashboard.Update(msg)
+				return m, cmd
+			}
+		case "1", "2", "3", "4", "5", "b", "tab", "shift+tab", "pgup", "pgdown":
 			if m.Screen == DashScreen{
 				var cmd tea.Cmd
 				m.dashboard, cmd = m.dashboard.Update(msg)
@@ -266,12 +539,21 @@ func(m model)View()string{
 		return s
 	case DashScreen:
 		s := m.dashboard.View()
+		if m.reauth {
+			return overlay(s, m.reauthModal(), m.width, m.height)
+		}
 		return s
 	case LoadingScreen:
 		return m.loader.View()
 	case ErrorScreen:
 		s := m.errorModel.View()
 		return s
+	case DocsScreen:
+		return m.docs.View()
+	case TradesScreen:
+		return m.trades.View()
+	case ReaderScreen:
+		return m.reader.View()
 	}
 	return ""
 }
@@ -279,100 +561,359 @@ func(m model)View()string{
 func main(){
 	f, _ := os.Create("alpstein.log")
     log.SetOutput(f)
-	godotenv.Load()
-	url := os.Getenv("BACKEND_URL")
-	oautClient := os.Getenv("OAUTH_CLIENT")
-	oautCb := os.Getenv("OAUTH_CB")
-
-
 	showVersion := flag.Bool("version", false, "print version and exit")
+	configFlags := config.RegisterFlags(flag.CommandLine)
     flag.Parse()
     if *showVersion {
         fmt.Println(version)
         os.Exit(0)
     }
+	// commands may be followed by more flags, e.g. "config show --profile work"
+	var command []string
+	for rest := flag.Args(); len(rest) > 0; {
+		if strings.HasPrefix(rest[0], "-") {
+			flag.CommandLine.Parse(rest)
+			rest = flag.Args()
+			continue
+		}
+		command = append(command, rest[0])
+		rest = rest[1:]
+	}
 
-	newModel := initModel(url,oautClient,oautCb)
+	cfg, cfgErr := configFlags.Resolve()
+	if cfgErr != nil {
+		fmt.Fprintln(os.Stderr, cfgErr)
+		os.Exit(2)
+	}
+
+	store, storeErr := creds.NewStore(cfg.Profile, cfg.Passphrase)
+	if storeErr != nil {
+		log.Println("credential store unavailable:", storeErr)
+	}
+
+	switch strings.Join(command, " ") {
+	case "":
+	case "logout":
+		if store == nil {
+			fmt.Fprintln(os.Stderr, storeErr)
+			os.Exit(1)
+		}
+		if err := store.Clear(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("logged out of profile %q\n", store.Profile)
+		os.Exit(0)
+	case "profiles":
+		listProfiles(cfg.Profile)
+		os.Exit(0)
+	case "config show":
+		fmt.Print(cfg.Show())
+		os.Exit(0)
+	case "config validate":
+		problems := cfg.Validate()
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, "✗", p)
+		}
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+		fmt.Println("config ok")
+		os.Exit(0)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected logout, profiles, config show or config validate\n", strings.Join(command, " "))
+		os.Exit(2)
+	}
+	if problems := cfg.Validate(); len(problems) > 0 {
+		for _, p := range problems {
+			log.Println("config:", p)
+		}
+		if cfg.Login != loginPaste && cfg.Login != loginLoopback && cfg.Login != loginDevice {
+			fmt.Fprintf(os.Stderr, "unknown login mode %q\n", cfg.Login)
+			os.Exit(2)
+		}
+	}
+
+	router, routerErr := market.NewRouter(market.Options{
+		Provider: cfg.Market,
+		Quote: cfg.Quote,
+		Overrides: cfg.MarketOverrides,
+		BinanceURL: cfg.BinanceWSURL,
+		BinanceRESTURL: cfg.BinanceRESTURL,
+		CoinbaseURL: cfg.CoinbaseWSURL,
+		CoinbaseRESTURL: cfg.CoinbaseRESTURL,
+	})
+	if routerErr != nil {
+		fmt.Fprintln(os.Stderr, routerErr)
+		os.Exit(2)
+	}
+
+	newModel := initModel(cfg.Config,store,router)
 	p := tea.NewProgram(*newModel,tea.WithAltScreen(),tea.WithMouseCellMotion())
+	newModel.feeds.Attach(p.Send)
 	p.Run()
+	newModel.feeds.Close()
 }
 
-func(m *model) generateSigninURL(){
+func listProfiles(current string) {
+	names, err := creds.Profiles()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, name := range names {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		state := "signed out"
+		if s, err := creds.NewStore(name, ""); err == nil && s.Exists() {
+			state = "signed in"
+		}
+		fmt.Printf("%s %-20s %s\n", marker, name, state)
+	}
+}
+
+func(m *model) oauthConfig() oauth2.Config{
+	return oauth2.Config{
+		ClientID:     m.OAUTH_CLIENT,
+		RedirectURL:  m.OAUTH_CB,
+		Scopes:       []string{"profile", "email"},
+		Endpoint:     google.Endpoint,
+	}
+}
+
+func(m *model) generateSigninURL()tea.Msg{
 	   var (
-    	googleOAuthConfig = &oauth2.Config{
-    		ClientID:     m.OAUTH_CLIENT, 
-    		RedirectURL:  m.OAUTH_CB, 
-    		Scopes:       []string{"profile", "email"}, 
-    		Endpoint:     google.Endpoint,
-    	}
+    	googleOAuthConfig = m.oauthConfig()
 		oauthStateString = "CLI-app" 
     )
 	url := googleOAuthConfig.AuthCodeURL(oauthStateString)
 	// fmt.Println(url)
-	openBrowser(url)    
+	return signinURLMsg{url: url, openErr: m.opener.Open(url)}
 }
 
-func openBrowser(url string) error {
-        cmd := "open"
-        args := []string{url}
-    return exec.Command(cmd, args...).Start()
+func (m *model) signIn() tea.Cmd {
+	switch m.loginMode {
+	case loginLoopback:
+		return m.startLoopback()
+	case loginDevice:
+		return m.startDevice()
+	}
+	return m.generateSigninURL
 }
 
-func (m *model) handleAuth() tea.Cmd {
-	auth := strings.Trim(m.t.Value(), "[]")
+func (m *model) startLoopback() tea.Cmd {
+	cfg := m.oauthConfig()
+	return func() tea.Msg {
+		lb, err := auth.StartLoopback()
+		if err != nil {
+			return ErrorMessage(err.Error())
+		}
+		url := lb.AuthCodeURL(cfg)
+		return loopbackStartedMsg{lb: lb, url: url, openErr: m.opener.Open(url)}
+	}
+}
 
+func (m *model) startDevice() tea.Cmd {
+	client := m.client
 	return func() tea.Msg {
-		resp, err := http.Get(
-			fmt.Sprintf("%scli-oauth/callback?auth-key=%s", m.BE_URL, auth),
-		)
+		dc, err := client.StartDevice(context.Background())
 		if err != nil {
-			return jwtResultMsg{err: err.Error()}
+			return ErrorMessage(err.Error())
 		}
-		defer resp.Body.Close()
+		return deviceStartedMsg{code: dc, qr: dc.QRCode()}
+	}
+}
 
-		var httpRes HttpResponse
-		if err := json.NewDecoder(resp.Body).Decode(&httpRes); err != nil {
+func (m *model) pollDevice(dc *auth.DeviceCode) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		jwt, err := client.PollDevice(context.Background(), dc)
+		if err != nil {
 			return jwtResultMsg{err: err.Error()}
 		}
+		return jwtResultMsg{jwt: jwt}
+	}
+}
 
-		if resp.StatusCode == http.StatusNotFound {
-			return jwtResultMsg{err: httpRes.Message}
+func waitForLoopback(lb *auth.Loopback) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		code, err := lb.Wait(ctx)
+		if err != nil {
+			return loopbackFailedMsg{lb: lb, err: err}
 		}
+		return loopbackCodeMsg{code: code, verifier: lb.Verifier, redirectURI: lb.RedirectURI}
+	}
+}
+
+func (m *model) setOpenErr(err error) {
+	m.openErr = ""
+	if err != nil {
+		log.Println("opening browser:", err)
+		m.openErr = err.Error()
+	}
+}
+
+func (m *model) handleAuth() tea.Cmd {
+	auth := strings.Trim(m.t.Value(), "[]")
+	client := m.client
+	return m.exchange(func(ctx context.Context) (string, error) {
+		return client.ExchangeAuthKey(ctx, auth)
+	})
+}
 
-		return jwtResultMsg{jwt: httpRes.Data}
+func (m *model) exchangeCode(msg loopbackCodeMsg) tea.Cmd {
+	client := m.client
+	return m.exchange(func(ctx context.Context) (string, error) {
+		return client.ExchangeCode(ctx, msg.code, msg.verifier, msg.redirectURI)
+	})
+}
+
+func (m *model) exchange(exchange func(ctx context.Context) (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		jwt, err := exchange(ctx)
+		if err != nil {
+			return jwtResultMsg{err: err.Error()}
+		}
+		return jwtResultMsg{jwt: jwt}
 	}
 }
 
-func(m *model) getUserDetails(jwt interface{})tea.Cmd{
+func(m *model) getUserDetails(jwt string)tea.Cmd{
+	client := m.client
 	return func () tea.Msg {	
-		req, err := http.NewRequest("GET", fmt.Sprintf("%s/user",m.BE_URL), nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		client.SetToken(jwt)
+		user, err := client.CurrentUser(ctx)
+		if api.IsUnauthorized(err) {
+			return tokenRejectedMsg{}
+		}
 		if err != nil {
-			// return m.handleError(err.Error())
 			return ErrorMessage(err.Error())
 		}
-		req.Header.Add("Authorization",fmt.Sprintf("Bearer %s",jwt))
-		
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		
-		if err != nil{
-			// return m.handleError(err.Error())
-			return ErrorMessage(err.Error())
+		return userMsg(user.Data.FirstName)
+	}
+}
+// logout tears the session down completely: sockets, stored token and
+// every bit of per-user state, then starts a fresh sign in.
+func (m model) logout() (tea.Model, tea.Cmd) {
+	if c, ok := m.dashboard.(interface{ Close() }); ok {
+		c.Close()
+	}
+	if m.store != nil {
+		if err := m.store.Clear(); err != nil {
+			log.Println("clearing credentials:", err)
 		}
-		defer resp.Body.Close()
-		
-	
-		user := User{}
-		if err := json.NewDecoder(resp.Body).Decode(&user); err != nil{
-				return m.handleError(err.Error())
+	}
+	if m.loopback != nil {
+		m.loopback.Close()
+	}
+	m.jwt = ""
+	m.client.SetToken("")
+	m.CurrUser = ""
+	m.dashboard = nil
+	m.loopback = nil
+	m.device = nil
+	m.deviceQR = ""
+	m.reauth = false
+	m.reauthErr = ""
+	m.signinURL = ""
+	m.openErr = ""
+	m.t.Reset()
+	m.Screen = AuthScreen
+	log.Println("logged out of profile", m.profile)
+	return m, tea.Batch(textinput.Blink, m.signIn())
+}
+
+// updateReauth handles keys while the session expired modal is up, the
+// dashboard underneath stays frozen until a new token arrives.
+func (m model) updateReauth(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "enter":
+		m.reauthErr = ""
+		switch m.loginMode {
+		case loginPaste:
+			return m, m.handleAuth()
+		case loginLoopback:
+			if m.loopback == nil {
+				return m, m.signIn()
+			}
+		case loginDevice:
+			if m.device == nil {
+				return m, m.signIn()
+			}
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.t, cmd = m.t.Update(msg)
+	return m, cmd
+}
+
+func (m *model) reauthModal() string {
+	var body string
+	switch m.loginMode {
+	case loginLoopback:
+		body = "finish signing in from your browser"
+		if m.loopback == nil {
+			body = "press enter to sign in again"
 		}
-		if resp.StatusCode == http.StatusNotFound {
-			// return m.handleError(user.Message)
-			return ErrorMessage(user.Message)
+	case loginDevice:
+		if m.device == nil {
+			body = "press enter to get a login code"
+		} else {
+			body = fmt.Sprintf("open %s\nand enter the code\n\n%s", m.device.VerificationURI, m.device.UserCode)
 		}
-		return userMsg(user.Data.FirstName)
+	default:
+		body = "paste a fresh auth key and hit enter\n\n" + m.t.View()
+	}
+	if m.openErr != "" && m.loginMode != loginDevice {
+		body += "\n\n" + m.openErr
 	}
+	if m.reauthErr != "" {
+		body += "\n\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#fb2c36")).Render(m.reauthErr)
+	}
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(m.primaryTextColor)).Render("Your session expired")
+	return lipgloss.NewStyle().
+		Width(60).
+		Padding(1, 2).
+		AlignHorizontal(lipgloss.Center).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(m.primaryTextColor)).
+		Foreground(lipgloss.Color(m.secondaryTextColor)).
+		Render(title + "\n\n" + body + "\n\nthe dashboard picks up where you left off")
+}
+
+// overlay draws fg centered on top of bg, both being rendered views.
+func overlay(bg, fg string, width, height int) string {
+	bgLines := strings.Split(bg, "\n")
+	fgLines := strings.Split(fg, "\n")
+	fgWidth := lipgloss.Width(fg)
+	x := max((width-fgWidth)/2, 0)
+	y := max((height-len(fgLines))/2, 0)
+	for i, line := range fgLines {
+		row := y + i
+		if row >= len(bgLines) {
+			break
+		}
+		left := ansi.Truncate(bgLines[row], x, "")
+		if w := ansi.StringWidth(left); w < x {
+			left += strings.Repeat(" ", x-w)
+		}
+		right := ansi.TruncateLeft(bgLines[row], x+fgWidth, "")
+		bgLines[row] = left + "\x1b[0m" + line + "\x1b[0m" + right
+	}
+	return strings.Join(bgLines, "\n")
 }
+
 func (m *model)handleError(msg string)tea.Cmd{
 	return func() tea.Msg {
 		return ErrorMessage(msg)
@@ -410,10 +951,31 @@ func (m *model) AuthScreen() string {
 	subBrandingStyle := lipgloss.NewStyle().Width(m.width).AlignHorizontal(lipgloss.Center).
 	Background(lipgloss.Color(m.bgColor)).Foreground(lipgloss.Color(m.secondaryTextColor))
 	subBranding := subBrandingStyle.Render("Stay ahead with intelligent crypto analysis. Intense, data heavy blogs cleansed and made actionable.")
+	if m.profile != "" && m.profile != creds.DefaultProfile {
+		subBranding += "\n" + subBrandingStyle.Render("profile: " + m.profile)
+	}
 
     message := "enter the auth key and hit enter"
+	inputView := m.t.View()
+	switch m.loginMode {
+	case loginLoopback:
+		message = "finish signing in from your browser, this screen moves on by itself"
+		inputView = "waiting for google... [enter] reopen browser"
+	case loginDevice:
+		if m.device == nil {
+			message = "waiting for a login code, press enter if none shows up"
+			inputView = ""
+		} else {
+			message = fmt.Sprintf("on any device open %s and enter the code below", m.device.VerificationURI)
+			inputView = m.device.UserCode
+		}
+	}
     messageCentered := lipgloss.NewStyle().Background(lipgloss.Color(m.bgColor)).Foreground(lipgloss.Color(m.primaryTextColor)).Width(m.width).AlignHorizontal(lipgloss.Center).Render(message)
-    messageHeight := 1
+	if m.openErr != "" && m.loginMode != loginDevice {
+		openErr := lipgloss.NewStyle().Foreground(lipgloss.Color(m.secondaryTextColor)).Width(m.width).Padding(0,4).AlignHorizontal(lipgloss.Center).Render(m.openErr)
+		messageCentered = lipgloss.JoinVertical(lipgloss.Left, messageCentered, "", openErr)
+	}
+    messageHeight := lipgloss.Height(messageCentered)
 
     // Input field
 	inputStyle := lipgloss.NewStyle().Width(m.width).AlignHorizontal(lipgloss.Center).Background(lipgloss.Color(m.bgColor))
@@ -421,9 +983,14 @@ func (m *model) AuthScreen() string {
     Width(45).
     AlignHorizontal(lipgloss.Center).
 	Background(lipgloss.Color(m.bgColor)).Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color(m.primaryTextColor)).Padding(0,1)
-	temp := containerStyle.Render(m.t.View())
+	temp := containerStyle.Render(inputView)
     centeredinput := inputStyle.Render(temp)
     inputHeight := lipgloss.Height(temp)
+	if m.loginMode == loginDevice && m.deviceQR != "" && m.device != nil {
+		qr := inputStyle.Render(m.deviceQR)
+		centeredinput = lipgloss.JoinVertical(lipgloss.Left, centeredinput, "", qr)
+		inputHeight += 1 + lipgloss.Height(qr)
+	}
 
     // Total content height (message + small gap + input)
     gap := 1
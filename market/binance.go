@@ -0,0 +1,147 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whiplashvin/alpstein-tui/feed"
+)
+
+type binance struct {
+	url  string
+	rest string
+}
+
+// Binance streams <pair>@ticker, <pair>@kline_<interval>, <pair>@depth20
+// and <pair>@aggTrade over a combined stream, the pairs live in the url so
+// a new set of coins means a new connection.
+func Binance(wsURL, restURL string) MarketData {
+	return binance{url: strings.TrimSuffix(wsURL, "/"), rest: strings.TrimSuffix(restURL, "/")}
+}
+
+func (binance) Name() string { return "binance" }
+
+func (binance) Topic(base, quote string) string {
+	return strings.ToLower(base+quote) + "@ticker"
+}
+
+func (binance) CandleTopic(base, quote string, iv Interval) string {
+	return strings.ToLower(base+quote) + "@kline_" + string(iv)
+}
+
+func (binance) DepthTopic(base, quote string) string {
+	return strings.ToLower(base+quote) + "@depth20"
+}
+
+func (binance) TradeTopic(base, quote string) string {
+	return strings.ToLower(base+quote) + "@aggTrade"
+}
+
+// binanceEvent holds every payload we subscribe to, binance keeps the
+// names short and tells them apart by e. Partial depth has no e and is
+// told apart by its stream name.
+//
+// encoding/json falls back to a case-insensitive match for keys without a
+// field of their own, so every key whose other case we read needs a field
+// here even if it goes unused, e.g. E would land in e and fail to decode.
+type binanceEvent struct {
+	Event     string `json:"e"`
+	EventTime int64  `json:"E"`
+	// p is the trade price on an aggTrade
+	PriceChange        json.Number `json:"p"`
+	PriceChangePercent json.Number `json:"P"`
+	LastPrice          json.Number `json:"c"`
+	CloseTime          int64       `json:"C"`
+	Kline              struct {
+		Start     int64       `json:"t"`
+		CloseTime int64       `json:"T"`
+		Open      json.Number `json:"o"`
+		High      json.Number `json:"h"`
+		Low       json.Number `json:"l"`
+		LastTrade int64       `json:"L"`
+		Close     json.Number `json:"c"`
+	} `json:"k"`
+	Bids       [][]json.Number `json:"bids"`
+	Asks       [][]json.Number `json:"asks"`
+	Quantity   json.Number     `json:"q"`
+	TradeTime  int64           `json:"T"`
+	BuyerMaker bool            `json:"m"`
+	// M is an always true flag binance says to ignore, it would otherwise
+	// land in m and make every trade a sell
+	Ignore bool `json:"M"`
+}
+
+func (b binance) Feed() feed.Config {
+	return feed.Config{
+		URL: func(streams []string) string {
+			return fmt.Sprintf("%s/stream?streams=%s", b.url, strings.Join(streams, "/"))
+		},
+		Decode: func(p []byte) (string, any, error) {
+			// combined streams wrap every event as {"stream":..,"data":..}
+			var frame struct {
+				Stream string       `json:"stream"`
+				Data   binanceEvent `json:"data"`
+			}
+			if err := json.Unmarshal(p, &frame); err != nil {
+				return "", nil, err
+			}
+			d := frame.Data
+			if strings.Contains(frame.Stream, "@depth") {
+				book := Book{Topic: frame.Stream, Bids: bookLevels(d.Bids), Asks: bookLevels(d.Asks), Time: time.Now()}
+				return frame.Stream, book, nil
+			}
+			if d.Event == "aggTrade" {
+				// the seller hit the bid when the buyer was the maker
+				t := Trade{Topic: frame.Stream, Buy: !d.BuyerMaker, Time: time.UnixMilli(d.TradeTime)}
+				t.Price, _ = d.PriceChange.Float64()
+				t.Size, _ = d.Quantity.Float64()
+				return frame.Stream, t, nil
+			}
+			if d.Event == "kline" {
+				c := Candle{Topic: frame.Stream, Start: time.UnixMilli(d.Kline.Start)}
+				c.Open, _ = d.Kline.Open.Float64()
+				c.High, _ = d.Kline.High.Float64()
+				c.Low, _ = d.Kline.Low.Float64()
+				c.Close, _ = d.Kline.Close.Float64()
+				return frame.Stream, c, nil
+			}
+			t := Ticker{Topic: frame.Stream, Time: time.UnixMilli(d.CloseTime)}
+			t.Last, _ = d.LastPrice.Float64()
+			t.Change, _ = d.PriceChange.Float64()
+			t.ChangePercent, _ = d.PriceChangePercent.Float64()
+			return frame.Stream, t, nil
+		},
+	}
+}
+
+func (b binance) Candles(ctx context.Context, base, quote string, iv Interval, limit int) ([]Candle, error) {
+	q := url.Values{
+		"symbol":   {strings.ToUpper(base + quote)},
+		"interval": {string(iv)},
+		"limit":    {strconv.Itoa(limit)},
+	}
+	// each row is [open time, open, high, low, close, volume, ...]
+	var rows [][]any
+	if err := getJSON(ctx, b.rest+"/api/v3/klines?"+q.Encode(), &rows); err != nil {
+		return nil, err
+	}
+	candles := make([]Candle, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 5 {
+			continue
+		}
+		start, _ := row[0].(float64)
+		c := Candle{Start: time.UnixMilli(int64(start))}
+		for i, dst := range []*float64{&c.Open, &c.High, &c.Low, &c.Close} {
+			s, _ := row[i+1].(string)
+			*dst, _ = strconv.ParseFloat(s, 64)
+		}
+		candles = append(candles, c)
+	}
+	return candles, nil
+}
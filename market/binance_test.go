@@ -0,0 +1,95 @@
+package market
+
+import (
+	"testing"
+	"time"
+)
+
+// frames as binance sends them on a combined stream, every key included
+// since a key we don't read can still collide with one we do.
+const (
+	tickerFrame = `{"stream":"btcusdt@ticker","data":{"e":"24hrTicker","E":1672515782136,"s":"BTCUSDT",` +
+		`"p":"-120.50000000","P":"-0.512","w":"23500.10000000","x":"23540.00000000","c":"23419.50000000",` +
+		`"Q":"0.01200000","b":"23419.40000000","B":"1.50000000","a":"23419.50000000","A":"2.10000000",` +
+		`"o":"23540.00000000","h":"23700.00000000","l":"23300.00000000","v":"18151.15000000",` +
+		`"q":"426573871.12000000","O":1672429382136,"C":1672515782136,"F":0,"L":18150,"n":18151}}`
+	klineFrame = `{"stream":"btcusdt@kline_1h","data":{"e":"kline","E":1672515782136,"s":"BTCUSDT",` +
+		`"k":{"t":1672513200000,"T":1672516799999,"s":"BTCUSDT","i":"1h","f":100,"L":200,` +
+		`"o":"23400.00000000","c":"23419.50000000","h":"23450.00000000","l":"23390.00000000",` +
+		`"v":"1000.00000000","n":100,"x":false,"q":"23410000.00000000","V":"500.00000000",` +
+		`"Q":"11705000.00000000","B":"0"}}}`
+)
+
+func decode(t *testing.T, frame string) (string, any) {
+	t.Helper()
+	topic, data, err := Binance("", "").Feed().Decode([]byte(frame))
+	if err != nil {
+		t.Fatalf("decoding %s: %v", frame, err)
+	}
+	return topic, data
+}
+
+func TestBinanceTicker(t *testing.T) {
+	topic, data := decode(t, tickerFrame)
+	tick, ok := data.(Ticker)
+	if !ok {
+		t.Fatalf("decoded a %T, want a Ticker", data)
+	}
+	if topic != "btcusdt@ticker" || tick.Topic != topic {
+		t.Errorf("topic = %q, ticker topic = %q", topic, tick.Topic)
+	}
+	if tick.Last != 23419.5 || tick.Change != -120.5 || tick.ChangePercent != -0.512 {
+		t.Errorf("ticker = %+v, want last 23419.5, change -120.5 (-0.512%%)", tick)
+	}
+	if !tick.Time.Equal(time.UnixMilli(1672515782136)) {
+		t.Errorf("time = %v, want the close time", tick.Time)
+	}
+}
+
+func TestBinanceKline(t *testing.T) {
+	_, data := decode(t, klineFrame)
+	c, ok := data.(Candle)
+	if !ok {
+		t.Fatalf("decoded a %T, want a Candle", data)
+	}
+	// the bar is stamped with its open time, not its close time, so the
+	// stream replaces the bar the rest api seeded
+	if !c.Start.Equal(time.UnixMilli(1672513200000)) {
+		t.Errorf("start = %v, want the open time", c.Start)
+	}
+	if c.Open != 23400 || c.High != 23450 || c.Low != 23390 || c.Close != 23419.5 {
+		t.Errorf("candle = %+v", c)
+	}
+}
+
+func TestBinanceAggTrade(t *testing.T) {
+	for _, tc := range []struct {
+		maker string
+		buy   bool
+	}{{"false", true}, {"true", false}} {
+		_, data := decode(t, `{"stream":"btcusdt@aggTrade","data":{"e":"aggTrade","E":1672515782136,"s":"BTCUSDT",`+
+			`"a":26129,"p":"23419.50","q":"0.75","f":100,"l":105,"T":1672515782130,"m":`+tc.maker+`,"M":true}}`)
+		tr, ok := data.(Trade)
+		if !ok {
+			t.Fatalf("decoded a %T, want a Trade", data)
+		}
+		if tr.Buy != tc.buy {
+			t.Errorf("m:%s decoded as buy = %v, want %v", tc.maker, tr.Buy, tc.buy)
+		}
+		if tr.Price != 23419.5 || tr.Size != 0.75 || !tr.Time.Equal(time.UnixMilli(1672515782130)) {
+			t.Errorf("trade = %+v", tr)
+		}
+	}
+}
+
+func TestBinanceDepth(t *testing.T) {
+	_, data := decode(t, `{"stream":"btcusdt@depth20","data":{"lastUpdateId":160,`+
+		`"bids":[["23419.40","1.5"],["23419.00","0.2"]],"asks":[["23419.50","2.1"]]}}`)
+	book, ok := data.(Book)
+	if !ok {
+		t.Fatalf("decoded a %T, want a Book", data)
+	}
+	if len(book.Bids) != 2 || len(book.Asks) != 1 || book.Bids[0] != (BookLevel{Price: 23419.4, Size: 1.5}) {
+		t.Errorf("book = %+v", book)
+	}
+}
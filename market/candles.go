@@ -0,0 +1,99 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Interval is a candle width in the short form both venues understand.
+type Interval string
+
+// Intervals are the widths the chart can switch between, in key order.
+var Intervals = []Interval{"1m", "15m", "1h", "4h", "1d"}
+
+func (iv Interval) Duration() time.Duration {
+	switch iv {
+	case "1m":
+		return time.Minute
+	case "15m":
+		return 15 * time.Minute
+	case "1h":
+		return time.Hour
+	case "4h":
+		return 4 * time.Hour
+	case "1d":
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// Candle is one OHLC bar. Topic is only set on candles that came off a
+// stream.
+type Candle struct {
+	Topic                  string
+	Start                  time.Time
+	Open, High, Low, Close float64
+}
+
+// Charting is implemented by venues that can seed and stream candles.
+type Charting interface {
+	// Candles fetches the newest limit candles over REST, oldest first.
+	Candles(ctx context.Context, base, quote string, iv Interval, limit int) ([]Candle, error)
+	// CandleTopic is the stream that keeps the newest candle moving on the
+	// venue's feed. It carries either Candle or Ticker frames.
+	CandleTopic(base, quote string, iv Interval) string
+}
+
+// Fold moves the newest candle with a ticker, opening a new one once the
+// ticker is past the end of the current bar.
+func Fold(candles []Candle, t Ticker, iv Interval) []Candle {
+	if len(candles) == 0 || t.Last == 0 {
+		return candles
+	}
+	last := &candles[len(candles)-1]
+	if start := t.Time.Truncate(iv.Duration()); start.After(last.Start) {
+		return append(candles, Candle{Start: start, Open: last.Close, High: t.Last, Low: t.Last, Close: t.Last})
+	}
+	last.Close = t.Last
+	last.High = max(last.High, t.Last)
+	last.Low = min(last.Low, t.Last)
+	return candles
+}
+
+// Merge puts a streamed candle in place, replacing the bar with the same
+// start or appending a newer one.
+func Merge(candles []Candle, c Candle) []Candle {
+	if n := len(candles); n > 0 {
+		switch {
+		case c.Start.Equal(candles[n-1].Start):
+			candles[n-1] = c
+			return candles
+		case c.Start.Before(candles[n-1].Start):
+			return candles
+		}
+	}
+	return append(candles, c)
+}
+
+var restClient = &http.Client{Timeout: 10 * time.Second}
+
+func getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := restClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s %s", url, resp.Status, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,151 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whiplashvin/alpstein-tui/feed"
+)
+
+type coinbase struct {
+	url  string
+	rest string
+}
+
+// Coinbase streams the exchange ticker and matches channels. Products are
+// added and dropped with subscribe messages on one long lived socket. Its
+// level2 channel needs a signed connection, so there is no order book from
+// here.
+func Coinbase(wsURL, restURL string) MarketData {
+	return coinbase{url: wsURL, rest: strings.TrimSuffix(restURL, "/")}
+}
+
+func (coinbase) Name() string { return "coinbase" }
+
+func (coinbase) Topic(base, quote string) string {
+	return strings.ToUpper(base) + "-" + strings.ToUpper(quote)
+}
+
+type coinbaseSub struct {
+	Type       string   `json:"type"`
+	ProductIDs []string `json:"product_ids"`
+	Channels   []string `json:"channels"`
+}
+
+// coinbaseTicker holds both a ticker and a match, side and size are only
+// set on the latter.
+type coinbaseTicker struct {
+	Type      string    `json:"type"`
+	ProductID string    `json:"product_id"`
+	Price     string    `json:"price"`
+	Open24h   string    `json:"open_24h"`
+	Size      string    `json:"size"`
+	Side      string    `json:"side"`
+	Time      time.Time `json:"time"`
+}
+
+// matches marks a trade topic, the rest are tickers. The channel rides in
+// the topic so one socket can carry both.
+const matches = "@matches"
+
+func (c coinbase) TradeTopic(base, quote string) string {
+	return c.Topic(base, quote) + matches
+}
+
+func coinbaseChannel(topic string) (product, channel string) {
+	if product, ok := strings.CutSuffix(topic, matches); ok {
+		return product, "matches"
+	}
+	return topic, "ticker"
+}
+
+func (c coinbase) Feed() feed.Config {
+	return feed.Config{
+		URL: func([]string) string { return c.url },
+		Sub: func(topic string) any {
+			product, channel := coinbaseChannel(topic)
+			return coinbaseSub{Type: "subscribe", ProductIDs: []string{product}, Channels: []string{channel}}
+		},
+		Unsub: func(topic string) any {
+			product, channel := coinbaseChannel(topic)
+			return coinbaseSub{Type: "unsubscribe", ProductIDs: []string{product}, Channels: []string{channel}}
+		},
+		Decode: func(p []byte) (string, any, error) {
+			var msg coinbaseTicker
+			if err := json.Unmarshal(p, &msg); err != nil {
+				return "", nil, err
+			}
+			if msg.Type == "match" || msg.Type == "last_match" {
+				t := Trade{Topic: msg.ProductID + matches, Time: msg.Time}
+				t.Price, _ = strconv.ParseFloat(msg.Price, 64)
+				t.Size, _ = strconv.ParseFloat(msg.Size, 64)
+				// side is the maker's, a resting sell was lifted by a buyer
+				t.Buy = msg.Side == "sell"
+				return t.Topic, t, nil
+			}
+			// subscriptions acks and the like carry no prices
+			if msg.Type != "ticker" {
+				return "", nil, feed.Skip
+			}
+			last, err := strconv.ParseFloat(msg.Price, 64)
+			if err != nil {
+				return "", nil, err
+			}
+			t := Ticker{Topic: msg.ProductID, Last: last, Time: msg.Time}
+			if open, err := strconv.ParseFloat(msg.Open24h, 64); err == nil && open != 0 {
+				t.Change = last - open
+				t.ChangePercent = t.Change / open * 100
+			}
+			return msg.ProductID, t, nil
+		},
+	}
+}
+
+// CandleTopic is the plain ticker, coinbase has no public candle channel
+// so the newest bar is folded from trades instead.
+func (c coinbase) CandleTopic(base, quote string, _ Interval) string {
+	return c.Topic(base, quote)
+}
+
+// Candles asks for the granularity directly, 4h isn't one coinbase offers
+// so it is built from 1h bars.
+func (c coinbase) Candles(ctx context.Context, base, quote string, iv Interval, limit int) ([]Candle, error) {
+	fetch, group := iv, 1
+	if iv == "4h" {
+		fetch, group = "1h", 4
+	}
+	// each row is [time, low, high, open, close, volume], newest first
+	var rows [][]float64
+	u := fmt.Sprintf("%s/products/%s/candles?granularity=%d", c.rest, c.Topic(base, quote), int(fetch.Duration().Seconds()))
+	if err := getJSON(ctx, u, &rows); err != nil {
+		return nil, err
+	}
+	var candles []Candle
+	for i := len(rows) - 1; i >= 0; i-- {
+		row := rows[i]
+		if len(row) < 5 {
+			continue
+		}
+		bar := Candle{Start: time.Unix(int64(row[0]), 0), Low: row[1], High: row[2], Open: row[3], Close: row[4]}
+		if group > 1 {
+			start := bar.Start.Truncate(iv.Duration())
+			if n := len(candles); n > 0 && candles[n-1].Start.Equal(start) {
+				last := &candles[n-1]
+				last.High = max(last.High, bar.High)
+				last.Low = min(last.Low, bar.Low)
+				last.Close = bar.Close
+				continue
+			}
+			bar.Start = start
+		}
+		candles = append(candles, bar)
+	}
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return candles, nil
+}
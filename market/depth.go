@@ -0,0 +1,48 @@
+package market
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BookLevel is one price on the order book and the size resting there.
+type BookLevel struct {
+	Price, Size float64
+}
+
+// Book is the top of the order book, best prices first on both sides.
+type Book struct {
+	Topic      string
+	Bids, Asks []BookLevel
+	Time       time.Time
+}
+
+// Spread is the gap between the best ask and the best bid, 0 while either
+// side is empty.
+func (b Book) Spread() float64 {
+	if len(b.Bids) == 0 || len(b.Asks) == 0 {
+		return 0
+	}
+	return b.Asks[0].Price - b.Bids[0].Price
+}
+
+// Depth is implemented by venues with a public order book stream. The
+// topic carries Book frames on the venue's feed.
+type Depth interface {
+	DepthTopic(base, quote string) string
+}
+
+// bookLevels reads the [["price","size"],...] rows both venues use.
+func bookLevels(rows [][]json.Number) []BookLevel {
+	levels := make([]BookLevel, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		var l BookLevel
+		l.Price, _ = row[0].Float64()
+		l.Size, _ = row[1].Float64()
+		levels = append(levels, l)
+	}
+	return levels
+}
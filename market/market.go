@@ -0,0 +1,156 @@
+// Package market hides where live prices come from. Each venue implements
+// MarketData and decodes its own ticker layout into a Ticker, a Router
+// then picks the venue and quote currency for every coin.
+package market
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whiplashvin/alpstein-tui/feed"
+)
+
+// Ticker is a venue neutral 24h ticker.
+type Ticker struct {
+	Topic         string
+	Last          float64
+	Change        float64
+	ChangePercent float64
+	Time          time.Time
+}
+
+// MarketData is a venue that streams tickers over a websocket.
+type MarketData interface {
+	Name() string
+	// Topic is the venue's stream name for base quoted in quote.
+	Topic(base, quote string) string
+	// Feed says how to reach the venue, its frames decode into Ticker.
+	Feed() feed.Config
+}
+
+// Options picks the default venue and quote, overrides is a comma
+// separated list of SYMBOL=provider or SYMBOL=provider:QUOTE.
+type Options struct {
+	Provider        string
+	Quote           string
+	Overrides       string
+	BinanceURL      string
+	BinanceRESTURL  string
+	CoinbaseURL     string
+	CoinbaseRESTURL string
+}
+
+type Override struct {
+	Provider string
+	Quote    string
+}
+
+// Router knows which venue and quote every coin is priced in.
+type Router struct {
+	quote     string
+	def       MarketData
+	overrides map[string]Override
+	venues    map[string]MarketData
+}
+
+// Names lists the providers that can be configured.
+var Names = []string{"binance", "coinbase"}
+
+func NewRouter(opts Options) (*Router, error) {
+	r := &Router{
+		quote: strings.ToUpper(opts.Quote),
+		venues: map[string]MarketData{
+			"binance":  Binance(opts.BinanceURL, opts.BinanceRESTURL),
+			"coinbase": Coinbase(opts.CoinbaseURL, opts.CoinbaseRESTURL),
+		},
+	}
+	if r.quote == "" {
+		r.quote = "USDT"
+	}
+	def, ok := r.venues[strings.ToLower(opts.Provider)]
+	if !ok {
+		return nil, fmt.Errorf("unknown market provider %q, expected one of %s", opts.Provider, strings.Join(Names, ", "))
+	}
+	r.def = def
+	overrides, err := ParseOverrides(opts.Overrides)
+	if err != nil {
+		return nil, err
+	}
+	r.overrides = overrides
+	return r, nil
+}
+
+// ParseOverrides reads "XYZ=coinbase,ABC=coinbase:USD".
+func ParseOverrides(raw string) (map[string]Override, error) {
+	out := map[string]Override{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		symbol, target, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(symbol) == "" {
+			return nil, fmt.Errorf("market override %q should look like SYMBOL=provider[:QUOTE]", part)
+		}
+		provider, quote, _ := strings.Cut(strings.TrimSpace(target), ":")
+		provider = strings.ToLower(provider)
+		if !slices.Contains(Names, provider) {
+			return nil, fmt.Errorf("market override %q: unknown provider %q", part, provider)
+		}
+		out[strings.ToUpper(strings.TrimSpace(symbol))] = Override{Provider: provider, Quote: strings.ToUpper(quote)}
+	}
+	return out, nil
+}
+
+// Route is where one coin is priced.
+type Route struct {
+	Venue MarketData
+	Base  string
+	Topic string
+	Quote string
+}
+
+// Route returns the venue, topic and quote symbol is priced in.
+func (r *Router) Route(symbol string) Route {
+	symbol = strings.ToUpper(symbol)
+	venue, quote := r.def, r.quote
+	if o, ok := r.overrides[symbol]; ok {
+		venue = r.venues[o.Provider]
+		if o.Quote != "" {
+			quote = o.Quote
+		}
+	}
+	return Route{Venue: venue, Base: symbol, Topic: venue.Topic(symbol, quote), Quote: quote}
+}
+
+// Venues lists every venue a route can land on, the default first.
+func (r *Router) Venues() []MarketData {
+	out := []MarketData{r.def}
+	for _, name := range Names {
+		venue := r.venues[name]
+		if venue == r.def {
+			continue
+		}
+		for _, o := range r.overrides {
+			if o.Provider == name {
+				out = append(out, venue)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// FormatPrice keeps two decimals for normal prices but enough significant
+// digits that sub cent coins don't all read 0.00.
+func FormatPrice(f float64) string {
+	if f >= 1 || f == 0 {
+		return strconv.FormatFloat(f, 'f', 2, 64)
+	}
+	decimals := 3 - int(math.Floor(math.Log10(f)))
+	return strconv.FormatFloat(f, 'f', decimals, 64)
+}
@@ -0,0 +1,18 @@
+package market
+
+import "time"
+
+// Trade is one print on the tape. Buy is true when the buyer was the
+// aggressor, i.e. the trade lifted an ask.
+type Trade struct {
+	Topic       string
+	Price, Size float64
+	Buy         bool
+	Time        time.Time
+}
+
+// Tape is implemented by venues with a public trade stream. The topic
+// carries Trade frames on the venue's feed.
+type Tape interface {
+	TradeTopic(base, quote string) string
+}
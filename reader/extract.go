@@ -0,0 +1,287 @@
+package reader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxPage caps how much of a page gets read, articles are far smaller.
+const maxPage = 5 << 20
+
+// Article is the readable part of a news page.
+type Article struct {
+	Title  string
+	Site   string
+	URL    string
+	Blocks []Block
+}
+
+// Block is one paragraph-like piece of the article, in page order.
+type Block struct {
+	Kind Kind
+	Text string
+}
+
+type Kind int
+
+const (
+	Paragraph Kind = iota
+	Heading
+	Item
+	Quote
+	Code
+)
+
+// ErrNoText is returned for pages with nothing that looks like an article,
+// e.g. a paywall or a page built entirely by scripts.
+var ErrNoText = errors.New("no article text on the page")
+
+// Fetch downloads link and pulls the article out of it.
+func Fetch(ctx context.Context, client *http.Client, link string) (*Article, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s answered %s", resp.Request.URL.Host, resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		if mt, _, _ := mime.ParseMediaType(ct); mt != "text/html" && mt != "application/xhtml+xml" {
+			return nil, fmt.Errorf("not a web page (%s)", mt)
+		}
+	}
+	a, err := Extract(io.LimitReader(resp.Body, maxPage))
+	if err != nil {
+		return nil, err
+	}
+	// after redirects, so the site is where the text came from
+	a.URL = resp.Request.URL.String()
+	if a.Site == "" {
+		a.Site = strings.TrimPrefix(resp.Request.URL.Hostname(), "www.")
+	}
+	return a, nil
+}
+
+// Extract finds the title and the text of the article in an html page. It
+// reads the <article> element, or <main> or the whole body when there is
+// none, and leaves out navigation, scripts, forms and the like.
+func Extract(r io.Reader) (*Article, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	a := &Article{}
+	var title, h1 string
+	var article, main, body *html.Node
+	for n := range doc.Descendants() {
+		if n.Type != html.ElementNode {
+			continue
+		}
+		switch n.DataAtom {
+		case atom.Meta:
+			prop := attr(n, "property")
+			if prop == "" {
+				prop = attr(n, "name")
+			}
+			switch prop {
+			case "og:title":
+				a.Title = clean(attr(n, "content"))
+			case "og:site_name":
+				a.Site = clean(attr(n, "content"))
+			}
+		case atom.Title:
+			if title == "" {
+				title = clean(text(n))
+			}
+		case atom.H1:
+			if h1 == "" {
+				h1 = clean(text(n))
+			}
+		case atom.Article:
+			if article == nil {
+				article = n
+			}
+		case atom.Main:
+			if main == nil {
+				main = n
+			}
+		case atom.Body:
+			body = n
+		}
+	}
+	if a.Title == "" {
+		a.Title = h1
+	}
+	if a.Title == "" {
+		a.Title = title
+	}
+
+	root := article
+	if root == nil {
+		root = main
+	}
+	if root == nil {
+		root = body
+	}
+	if root != nil {
+		a.Blocks = blocks(root, nil)
+	}
+	// the headline is already the title
+	if len(a.Blocks) > 0 && a.Blocks[0].Kind == Heading && strings.EqualFold(a.Blocks[0].Text, h1) {
+		a.Blocks = a.Blocks[1:]
+	}
+	if !hasText(a.Blocks) {
+		return nil, ErrNoText
+	}
+	return a, nil
+}
+
+// skipped are elements that never hold article text.
+var skipped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Form: true, atom.Button: true, atom.Iframe: true, atom.Svg: true,
+	atom.Figure: true, atom.Select: true,
+}
+
+var kinds = map[atom.Atom]Kind{
+	atom.P: Paragraph, atom.Li: Item, atom.Blockquote: Quote, atom.Pre: Code,
+	atom.H1: Heading, atom.H2: Heading, atom.H3: Heading, atom.H4: Heading, atom.H5: Heading, atom.H6: Heading,
+}
+
+// inline are the elements that sit inside a line of text rather than
+// starting a new one.
+var inline = map[atom.Atom]bool{
+	atom.A: true, atom.Span: true, atom.Strong: true, atom.B: true, atom.Em: true, atom.I: true,
+	atom.U: true, atom.S: true, atom.Code: true, atom.Small: true, atom.Sub: true, atom.Sup: true,
+	atom.Mark: true, atom.Abbr: true, atom.Time: true, atom.Q: true, atom.Cite: true, atom.Br: true,
+}
+
+// blocks walks n in page order and turns every paragraph, heading, list
+// item, quote and code block into a Block. Text sitting loose in a div,
+// links and all, counts as a paragraph.
+func blocks(n *html.Node, out []Block) []Block {
+	var loose strings.Builder
+	flush := func() {
+		if t := clean(loose.String()); len(t) > 1 {
+			out = append(out, Block{Kind: Paragraph, Text: t})
+		}
+		loose.Reset()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.TextNode:
+			loose.WriteString(c.Data)
+		case c.Type != html.ElementNode || skipped[c.DataAtom] || hidden(c):
+		case inline[c.DataAtom]:
+			loose.WriteString(text(c))
+		default:
+			flush()
+			kind, ok := kinds[c.DataAtom]
+			if !ok {
+				out = blocks(c, out)
+				continue
+			}
+			t := text(c)
+			if kind != Code {
+				t = clean(t)
+			}
+			if t = strings.Trim(t, "\n"); strings.TrimSpace(t) != "" {
+				out = append(out, Block{Kind: kind, Text: t})
+			}
+		}
+	}
+	flush()
+	return out
+}
+
+// hidden catches the cookie banners and share bars that have no semantic
+// element of their own.
+func hidden(n *html.Node) bool {
+	if hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	class := strings.ToLower(attr(n, "class") + " " + attr(n, "id"))
+	for _, word := range []string{"cookie", "share", "newsletter", "byline", "related", "advert", "promo"} {
+		if strings.Contains(class, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasText reports whether anything besides headings came out, a page of
+// bare headings is most likely an index rather than an article.
+func hasText(blocks []Block) bool {
+	for _, b := range blocks {
+		if b.Kind != Heading {
+			return true
+		}
+	}
+	return false
+}
+
+// text is all the text under n, line breaks kept for <br>.
+func text(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.TextNode:
+				b.WriteString(c.Data)
+			case c.Type != html.ElementNode || skipped[c.DataAtom]:
+			case c.DataAtom == atom.Br:
+				b.WriteString("\n")
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// clean collapses the whitespace html doesn't care about.
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// valid reports whether u is something worth fetching.
+func valid(u string) bool {
+	p, err := url.Parse(u)
+	return err == nil && (p.Scheme == "http" || p.Scheme == "https") && p.Host != ""
+}
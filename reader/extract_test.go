@@ -0,0 +1,108 @@
+package reader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// serve answers every request with body as contentType.
+func serve(t *testing.T, contentType, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchArticle(t *testing.T) {
+	page, err := os.ReadFile("testdata/article.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := serve(t, "text/html; charset=utf-8", string(page))
+
+	a, err := Fetch(context.Background(), srv.Client(), srv.URL+"/news/btc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Bitcoin traders eye a breakout as funding flips"; a.Title != want {
+		t.Errorf("title = %q, want %q", a.Title, want)
+	}
+	if a.Site != "Mock Crypto News" {
+		t.Errorf("site = %q, want the og:site_name", a.Site)
+	}
+	if a.URL != srv.URL+"/news/btc" {
+		t.Errorf("url = %q, want %q", a.URL, srv.URL+"/news/btc")
+	}
+
+	var kinds []Kind
+	var text []string
+	for _, b := range a.Blocks {
+		kinds = append(kinds, b.Kind)
+		text = append(text, b.Text)
+	}
+	want := []Kind{Paragraph, Paragraph, Heading, Item, Item, Item, Quote, Paragraph}
+	if !slices.Equal(kinds, want) {
+		t.Fatalf("kinds = %v, want %v\n%s", kinds, want, strings.Join(text, "\n"))
+	}
+	if got := a.Blocks[1].Text; !strings.Contains(got, "Open interest rose 12% over") {
+		t.Errorf("inline markup should stay in the paragraph, got %q", got)
+	}
+	if got := a.Blocks[2].Text; got != "What the desks are watching" {
+		t.Errorf("heading = %q", got)
+	}
+
+	all := strings.Join(text, "\n")
+	for _, clutter := range []string{
+		"Markets",     // nav
+		"cookies",     // cookie banner
+		"Tweet",       // share bar
+		"Terms",       // footer
+		"Jane Doe",    // byline
+		"Most read",   // aside
+		"4h chart",    // figure caption
+		"analytics",   // scripts
+		"font-family", // styles
+	} {
+		if strings.Contains(all, clutter) {
+			t.Errorf("%q should have been left out:\n%s", clutter, all)
+		}
+	}
+}
+
+func TestFetchNoText(t *testing.T) {
+	srv := serve(t, "text/html", `<html><head><title>App</title></head><body>
+<nav><a href="/">Home</a></nav><div id="root"></div><script>render()</script></body></html>`)
+
+	_, err := Fetch(context.Background(), srv.Client(), srv.URL)
+	if !errors.Is(err, ErrNoText) {
+		t.Fatalf("err = %v, want ErrNoText", err)
+	}
+}
+
+func TestFetchNotHTML(t *testing.T) {
+	srv := serve(t, "application/json", `{"title": "not an article"}`)
+
+	_, err := Fetch(context.Background(), srv.Client(), srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "not a web page (application/json)") {
+		t.Fatalf("err = %v, want a not a web page error", err)
+	}
+}
+
+func TestFetchStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	_, err := Fetch(context.Background(), srv.Client(), srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "404 Not Found") {
+		t.Fatalf("err = %v, want the 404", err)
+	}
+}
@@ -0,0 +1,235 @@
+// Package reader shows the news article behind a signal inside the
+// terminal, for when there is no browser to hand it to, e.g. over ssh.
+package reader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/whiplashvin/alpstein-tui/browser"
+)
+
+// maxWidth keeps lines short enough to read on wide terminals.
+const maxWidth = 100
+
+// Close asks to go back to the dashboard.
+type Close struct{}
+
+// Loaded carries the article fetched for url, or why it couldn't be.
+type Loaded struct {
+	url     string
+	Article *Article
+	Err     error
+}
+
+// Opened reports how handing the article to the browser went.
+type Opened struct {
+	Err error
+}
+
+type model struct {
+	Width              int
+	Height             int
+	primaryTextColor   string
+	secondaryTextColor string
+	tertiaryTextColor  string
+	url                string
+	opener             browser.Opener
+	client             *http.Client
+	viewport           viewport.Model
+	article            *Article
+	loading            bool
+	err                string
+	status             string
+	renderedWidth      int
+}
+
+func InitReader(url string, opener browser.Opener, width, height int) *model {
+	m := &model{
+		primaryTextColor:   "#a3b3ff",
+		secondaryTextColor: "#c7d8ff",
+		tertiaryTextColor:  "#52525c",
+		url:                url,
+		opener:             opener,
+		client:             &http.Client{Timeout: 15 * time.Second},
+		viewport:           viewport.New(0, 0),
+		loading:            true,
+	}
+	m.resize(width, height)
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	return m.fetch()
+}
+
+func (m model) fetch() tea.Cmd {
+	client, url := m.client, m.url
+	return func() tea.Msg {
+		if !valid(url) {
+			return Loaded{url: url, Err: fmt.Errorf("%q is not a web address", url)}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		a, err := Fetch(ctx, client, url)
+		if err != nil {
+			log.Println("reader:", err)
+		}
+		return Loaded{url: url, Article: a, Err: err}
+	}
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height)
+		return m, nil
+	case Loaded:
+		if msg.url != m.url {
+			return m, nil
+		}
+		m.loading = false
+		m.err = ""
+		if msg.Err != nil {
+			m.err = msg.Err.Error()
+			return m, nil
+		}
+		m.article = msg.Article
+		m.renderedWidth = 0
+		m.resize(m.Width, m.Height)
+		m.viewport.GotoTop()
+		return m, nil
+	case Opened:
+		m.status = "opened in your browser"
+		if msg.Err != nil {
+			m.status = msg.Err.Error()
+		}
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "o":
+			opener, url := m.opener, m.url
+			return m, func() tea.Msg { return Opened{Err: opener.Open(url)} }
+		case "r":
+			if m.err == "" || m.loading {
+				return m, nil
+			}
+			m.loading = true
+			m.err = ""
+			return m, m.fetch()
+		case "g", "home":
+			m.viewport.GotoTop()
+			return m, nil
+		case "G", "end":
+			m.viewport.GotoBottom()
+			return m, nil
+		case "esc", "q":
+			return m, func() tea.Msg { return Close{} }
+		}
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// resize lays the viewport out again and re-renders the article when the
+// width changed, it is wrapped to it.
+func (m *model) resize(width, height int) {
+	m.Width, m.Height = width, height
+	m.viewport.Width = max(width-4, 0)
+	// title, blank line, the status and footer lines and the margin
+	m.viewport.Height = max(height-5, 0)
+	if m.article == nil || width == m.renderedWidth {
+		return
+	}
+	m.renderedWidth = width
+	m.viewport.SetContent(m.render(min(width-8, maxWidth)))
+}
+
+// render lays the article out as styled text, wrapped to width.
+func (m *model) render(width int) string {
+	width = max(width, 20)
+	a := m.article
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor))
+	text := lipgloss.NewStyle().Width(width).Foreground(lipgloss.Color(m.secondaryTextColor))
+	heading := lipgloss.NewStyle().Width(width).Bold(true).Foreground(lipgloss.Color(m.primaryTextColor))
+
+	var b strings.Builder
+	b.WriteString(heading.Render(a.Title))
+	b.WriteString("\n")
+	b.WriteString(dim.Width(width).Render(a.Site + " · " + a.URL))
+	for i, block := range a.Blocks {
+		// list items stay together, everything else gets a blank line
+		if block.Kind == Item && i > 0 && a.Blocks[i-1].Kind == Item {
+			b.WriteString("\n")
+		} else {
+			b.WriteString("\n\n")
+		}
+		switch block.Kind {
+		case Heading:
+			b.WriteString(heading.Render(block.Text))
+		case Item:
+			b.WriteString(hang("• ", text.Width(width-2).Render(block.Text)))
+		case Quote:
+			b.WriteString(hang("│ ", text.Width(width-2).Italic(true).Render(block.Text)))
+		case Code:
+			b.WriteString(dim.Render(block.Text))
+		default:
+			b.WriteString(text.Render(block.Text))
+		}
+	}
+	return b.String()
+}
+
+// hang puts mark in front of the first line of s and indents the rest to
+// match, so wrapped list items and quotes line up.
+func hang(mark, s string) string {
+	lines := strings.Split(s, "\n")
+	indent := strings.Repeat(" ", lipgloss.Width(mark))
+	if strings.TrimSpace(mark) == "│" {
+		indent = mark
+	}
+	for i := range lines {
+		if i == 0 {
+			lines[i] = mark + lines[i]
+		} else {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m model) View() string {
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor))
+	title := lipgloss.NewStyle().Foreground(lipgloss.Color(m.secondaryTextColor)).MarginLeft(2).MarginTop(1).
+		Render("ALPSTEIN · news")
+
+	var body, footer string
+	switch {
+	case m.loading:
+		body = dim.Render("fetching " + m.url + " ...")
+		footer = "[o] open in browser [esc] back"
+	case m.err != "":
+		body = lipgloss.NewStyle().Width(max(m.Width-8, 20)).Foreground(lipgloss.Color("#fb2c36")).
+			Render("couldn't read the article: "+m.err) + "\n\n" +
+			dim.Render("the browser may still manage it, press [o]")
+		footer = "[r] retry [o] open in browser [esc] back"
+	default:
+		body = m.viewport.View()
+		footer = fmt.Sprintf("%3.f%%  [▲/▼] scroll [o] open in browser [esc] back", m.viewport.ScrollPercent()*100)
+	}
+	body = lipgloss.NewStyle().MarginLeft(2).Height(max(m.Height-5, 0)).Render(body)
+
+	status := lipgloss.NewStyle().Width(m.Width - 2).MaxHeight(1).MarginLeft(2).AlignHorizontal(lipgloss.Center).
+		Foreground(lipgloss.Color(m.secondaryTextColor)).Render(m.status)
+	footer = lipgloss.NewStyle().Width(m.Width - 2).MarginLeft(2).AlignHorizontal(lipgloss.Center).
+		Foreground(lipgloss.Color(m.tertiaryTextColor)).Render(footer)
+	return lipgloss.JoinVertical(lipgloss.Left, title, body, status, footer)
+}
@@ -0,0 +1,221 @@
+package trades
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/whiplashvin/alpstein-tui/api"
+	"github.com/whiplashvin/alpstein-tui/market"
+)
+
+// column widths of the table, the symbol and name take whatever is left
+// over.
+const (
+	sideWidth     = 6
+	priceWidth    = 12
+	timeWidth     = 13
+	durationWidth = 9
+	pnlWidth      = 10
+	minSymbol     = 8
+)
+
+// visibleRows is how many trades fit between the header and the footer.
+func (m *model) visibleRows() int {
+	// title and the blank line, summary, column header, footer and margin
+	return max(m.Height-7, 1)
+}
+
+func (m model) View() string {
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor))
+	title := lipgloss.NewStyle().Foreground(lipgloss.Color(m.secondaryTextColor)).MarginLeft(2).MarginTop(1).
+		Render("ALPSTEIN · trades")
+
+	width := max(m.Width-4, 0)
+	var body string
+	switch {
+	case m.loading && len(m.trades) == 0:
+		body = dim.Render("loading trades...")
+	case m.err != "":
+		body = lipgloss.NewStyle().Foreground(lipgloss.Color("#fb2c36")).Render(m.err) + dim.Render("  [r] retry")
+	case len(m.trades) == 0:
+		body = dim.Render("no signal has triggered yet")
+	default:
+		body = m.summary() + "\n" + m.table(width)
+	}
+	body = lipgloss.NewStyle().MarginLeft(2).Width(width).Height(max(m.Height-4, 0)).Render(body)
+
+	var footer string
+	switch {
+	case m.searching:
+		footer = m.search.View()
+	default:
+		footer = "[s/S] sort [f] filter [/] search [enter] open [r] refresh [esc] back"
+	}
+	footer = lipgloss.NewStyle().Width(m.Width - 2).MarginLeft(2).AlignHorizontal(lipgloss.Center).
+		Foreground(lipgloss.Color(m.tertiaryTextColor)).Render(footer)
+	return lipgloss.JoinVertical(lipgloss.Left, title, body, footer)
+}
+
+func arrow(asc bool) string {
+	if asc {
+		return "↑"
+	}
+	return "↓"
+}
+
+// summary counts what is on screen and how the closed trades did, with
+// the sort and filters in use on the right.
+func (m *model) summary() string {
+	var open, closed, won int
+	var realized float64
+	for _, t := range m.rows {
+		if t.Status != "closed" {
+			open++
+			continue
+		}
+		closed++
+		if p, ok := m.profit(t); ok {
+			realized += p
+			if p > 0 {
+				won++
+			}
+		}
+	}
+	s := fmt.Sprintf("%d open · %d closed", open, closed)
+	if closed > 0 {
+		s += fmt.Sprintf(" · %.0f%% won · ", float64(won)/float64(closed)*100) + m.pnlStyle(realized).Render(signed(realized))
+	}
+	s = lipgloss.NewStyle().Foreground(lipgloss.Color(m.secondaryTextColor)).Render(s)
+
+	state := fmt.Sprintf("%s %s · %s", sortNames[m.sortBy], arrow(m.asc), statusNames[m.status])
+	if m.query != "" {
+		state += fmt.Sprintf(" · %q", m.query)
+	}
+	state = lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor)).Render(state)
+	gap := max(m.Width-4-lipgloss.Width(s)-lipgloss.Width(state), 2)
+	return s + strings.Repeat(" ", gap) + state
+}
+
+// table lays the rows out in width, leaving the timestamps out when they
+// don't fit.
+func (m *model) table(width int) string {
+	fixed := 2 + sideWidth + 2*priceWidth + durationWidth + pnlWidth
+	times := width-fixed-2*timeWidth >= minSymbol
+	if times {
+		fixed += 2 * timeWidth
+	}
+	symbolWidth := max(width-fixed, minSymbol)
+	header := "  " + pad("SYMBOL", symbolWidth) + pad("SIDE", sideWidth) + lpad("ENTRY", priceWidth) + lpad("EXIT", priceWidth)
+	if times {
+		header += lpad("TRIGGERED", timeWidth) + lpad("CLOSED", timeWidth)
+	}
+	header = lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor)).Render(
+		header + lpad("HELD", durationWidth) + lpad("P&L", pnlWidth))
+	lines := []string{header}
+	if len(m.rows) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color(m.tertiaryTextColor)).Render("  nothing matches"))
+	}
+	now := time.Now()
+	end := min(m.offset+m.visibleRows(), len(m.rows))
+	for i := m.offset; i < end; i++ {
+		lines = append(lines, m.row(m.rows[i], i == m.cursor, symbolWidth, times, now))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// row is one trade. The mark on the left is the ✵ of the sidebar for
+// trades still open, the P&L of those is live and shown with a ~.
+func (m *model) row(t api.CryptoModel, selected bool, symbolWidth int, times bool, now time.Time) string {
+	base := lipgloss.NewStyle().Foreground(lipgloss.Color(m.primaryTextColor))
+	if selected {
+		base = base.Background(lipgloss.Color(m.borderColor))
+	}
+	mark := "  "
+	if t.Status == "triggered" {
+		mark = "✵ "
+	}
+	exit, closedAt := "-", "-"
+	if t.ExitPrice > 0 {
+		exit = market.FormatPrice(t.ExitPrice)
+	}
+	if t.ClosureAt > 0 {
+		closedAt = stamp(t.ClosureAt)
+	}
+	var at string
+	if times {
+		triggeredAt := "-"
+		if t.TriggeredAt > 0 {
+			triggeredAt = stamp(t.TriggeredAt)
+		}
+		at = lpad(triggeredAt, timeWidth) + lpad(closedAt, timeWidth)
+	}
+	side := base.Foreground(lipgloss.Color("#00c950"))
+	if t.TriggeredPosition == "short" {
+		side = side.Foreground(lipgloss.Color("#fb2c36"))
+	}
+	pnl := base.Foreground(lipgloss.Color(m.tertiaryTextColor)).Render(lpad("-", pnlWidth))
+	if p, ok := m.profit(t); ok {
+		s := signed(p)
+		if t.Status != "closed" {
+			s = "~" + s
+		}
+		pnl = m.pnlStyle(p).Inherit(base).Render(lpad(s, pnlWidth))
+	}
+	symbol := t.Symbol + " "
+	if ansi.StringWidth(symbol) > symbolWidth {
+		symbol = pad(t.Symbol, symbolWidth)
+	}
+	name := ""
+	if t.Name != t.Symbol {
+		name = t.Name
+	}
+	return base.Render(mark+symbol) +
+		base.Foreground(lipgloss.Color(m.tertiaryTextColor)).Render(pad(name, symbolWidth-ansi.StringWidth(symbol))) +
+		side.Render(pad(t.TriggeredPosition, sideWidth)) +
+		base.Render(lpad(market.FormatPrice(entry(t)), priceWidth)+lpad(exit, priceWidth)+
+			at+lpad(held(duration(t, now)), durationWidth)) +
+		pnl
+}
+
+func (m *model) pnlStyle(p float64) lipgloss.Style {
+	if p < 0 {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#fb2c36"))
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#00c950"))
+}
+
+func signed(p float64) string {
+	if p < 0 {
+		return fmt.Sprintf("-%.2f%%", math.Abs(p))
+	}
+	return fmt.Sprintf("+%.2f%%", p)
+}
+
+func stamp(ms int64) string {
+	return time.UnixMilli(ms).Local().Format("Jan 02 15:04")
+}
+
+// held is a duration cut down to its two largest units, e.g. 3d4h or 2h10m.
+func held(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+func pad(s string, width int) string {
+	s = ansi.Truncate(s, width-1, "…")
+	return s + strings.Repeat(" ", max(width-ansi.StringWidth(s), 0))
+}
+
+func lpad(s string, width int) string {
+	s = ansi.Truncate(s, width-1, "…")
+	return strings.Repeat(" ", max(width-ansi.StringWidth(s), 0)) + s
+}
@@ -0,0 +1,375 @@
+// Package trades is the trade log: every signal whose entry was hit, still
+// open or closed, with how long it ran and what it made.
+package trades
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/whiplashvin/alpstein-tui/api"
+	"github.com/whiplashvin/alpstein-tui/dash"
+	"github.com/whiplashvin/alpstein-tui/feed"
+)
+
+// Close asks to go back to the dashboard.
+type Close struct{}
+
+// Loaded carries the trades fetched from the api, or why they couldn't be.
+type Loaded struct {
+	Trades []api.CryptoModel
+	Err    error
+}
+
+// sortKey is the column the table is ordered by, s cycles through them.
+type sortKey int
+
+const (
+	byTriggered sortKey = iota
+	byClosed
+	byDuration
+	byPnl
+	bySymbol
+	sortKeys
+)
+
+var sortNames = [...]string{"triggered", "closed", "duration", "p&l", "symbol"}
+
+// status narrows the table down, f cycles through them.
+type status int
+
+const (
+	allTrades status = iota
+	openTrades
+	closedTrades
+	winners
+	losers
+	statuses
+)
+
+var statusNames = [...]string{"all", "open", "closed", "winners", "losers"}
+
+type model struct {
+	Width              int
+	Height             int
+	primaryTextColor   string
+	secondaryTextColor string
+	tertiaryTextColor  string
+	borderColor        string
+	client             api.Backend
+	trades             []api.CryptoModel
+	pnl                map[string]dash.WSResp
+	loading            bool
+	err                string
+	sortBy             sortKey
+	asc                bool
+	status             status
+	search             textinput.Model
+	searching          bool
+	query              string
+	rows               []api.CryptoModel
+	cursor             int
+	offset             int
+}
+
+func InitTrades(client api.Backend, width, height int) *model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "symbol or name"
+	return &model{
+		Width:              width,
+		Height:             height,
+		primaryTextColor:   "#a3b3ff",
+		secondaryTextColor: "#c7d8ff",
+		tertiaryTextColor:  "#52525c",
+		borderColor:        "#27272a",
+		client:             client,
+		pnl:                map[string]dash.WSResp{},
+		loading:            true,
+		search:             ti,
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return m.fetch()
+}
+
+func (m model) fetch() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		trades, err := client.Trades(ctx)
+		return Loaded{Trades: trades, Err: err}
+	}
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.Width, m.Height = msg.Width, msg.Height
+		m.scroll()
+		return m, nil
+	case Loaded:
+		m.loading = false
+		if msg.Err != nil {
+			m.err = msg.Err.Error()
+			if api.IsUnauthorized(msg.Err) {
+				m.err = "your session expired, go back to the dashboard to sign in again"
+			}
+			return m, nil
+		}
+		m.err = ""
+		m.trades = msg.Trades
+		m.apply()
+		return m, watch(m.open())
+	case feed.Frame:
+		if res, ok := msg.Data.(dash.WSResp); ok && res.Id != "" {
+			m.pnl[res.Id] = res
+			if m.sortBy == byPnl || m.status == winners || m.status == losers {
+				m.apply()
+			}
+		}
+		return m, nil
+	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		switch msg.String() {
+		case "up", "k":
+			m.move(-1)
+		case "down", "j":
+			m.move(1)
+		case "pgup":
+			m.move(-m.visibleRows())
+		case "pgdown":
+			m.move(m.visibleRows())
+		case "g", "home":
+			m.move(-len(m.rows))
+		case "G", "end":
+			m.move(len(m.rows))
+		case "s":
+			m.sortBy = (m.sortBy + 1) % sortKeys
+			m.asc = m.sortBy == bySymbol
+			m.apply()
+		case "S":
+			m.asc = !m.asc
+			m.apply()
+		case "f":
+			m.status = (m.status + 1) % statuses
+			m.apply()
+		case "/":
+			m.searching = true
+			m.search.SetValue(m.query)
+			m.search.CursorEnd()
+			return m, m.search.Focus()
+		case "r":
+			if m.loading {
+				return m, nil
+			}
+			m.loading = true
+			return m, m.fetch()
+		case "enter":
+			if m.cursor >= len(m.rows) {
+				return m, nil
+			}
+			id := m.rows[m.cursor].Id
+			return m, tea.Batch(watch(nil), func() tea.Msg { return dash.SetCryptoId(id) }, closeCmd)
+		case "esc", "q":
+			if m.query != "" {
+				m.query = ""
+				m.apply()
+				return m, nil
+			}
+			return m, tea.Batch(watch(nil), closeCmd)
+		}
+	}
+	return m, nil
+}
+
+func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "esc":
+		m.searching = false
+		m.search.Blur()
+		if msg.String() == "enter" {
+			m.query = strings.TrimSpace(m.search.Value())
+			m.apply()
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	return m, cmd
+}
+
+func closeCmd() tea.Msg {
+	return Close{}
+}
+
+// watch has the dashboard stream the P&L of the given signals too, nil
+// hands the socket back to the page.
+func watch(ids []string) tea.Cmd {
+	return func() tea.Msg {
+		return dash.WatchSignals(ids)
+	}
+}
+
+// open is the ids of the trades still running, the only ones with a live
+// P&L.
+func (m *model) open() []string {
+	var ids []string
+	for _, t := range m.trades {
+		if t.Status == "triggered" {
+			ids = append(ids, t.Id)
+		}
+	}
+	return ids
+}
+
+// apply filters and sorts the trades into the rows on screen, keeping the
+// cursor on the same trade where it can.
+func (m *model) apply() {
+	var selected string
+	if m.cursor < len(m.rows) {
+		selected = m.rows[m.cursor].Id
+	}
+	q := strings.ToLower(m.query)
+	m.rows = nil
+	for _, t := range m.trades {
+		if q != "" && !strings.Contains(strings.ToLower(t.Symbol), q) && !strings.Contains(strings.ToLower(t.Name), q) {
+			continue
+		}
+		if m.keep(t) {
+			m.rows = append(m.rows, t)
+		}
+	}
+	slices.SortStableFunc(m.rows, m.compare)
+	m.cursor = max(slices.IndexFunc(m.rows, func(t api.CryptoModel) bool { return t.Id == selected }), 0)
+	m.scroll()
+}
+
+func (m *model) keep(t api.CryptoModel) bool {
+	switch m.status {
+	case openTrades:
+		return t.Status == "triggered"
+	case closedTrades:
+		return t.Status == "closed"
+	case winners:
+		p, ok := m.profit(t)
+		return ok && p > 0
+	case losers:
+		p, ok := m.profit(t)
+		return ok && p < 0
+	}
+	return true
+}
+
+// compare orders two trades by the sort column. Trades without a value
+// for it, like the closing time of an open one, go last either way.
+func (m *model) compare(a, b api.CryptoModel) int {
+	var x, y float64
+	var xok, yok bool
+	switch m.sortBy {
+	case bySymbol:
+		c := strings.Compare(a.Symbol, b.Symbol)
+		if !m.asc {
+			c = -c
+		}
+		return c
+	case byTriggered:
+		x, xok = float64(a.TriggeredAt), a.TriggeredAt > 0
+		y, yok = float64(b.TriggeredAt), b.TriggeredAt > 0
+	case byClosed:
+		x, xok = float64(a.ClosureAt), a.ClosureAt > 0
+		y, yok = float64(b.ClosureAt), b.ClosureAt > 0
+	case byDuration:
+		x, xok = float64(duration(a, time.Now())), a.TriggeredAt > 0
+		y, yok = float64(duration(b, time.Now())), b.TriggeredAt > 0
+	case byPnl:
+		x, xok = m.profit(a)
+		y, yok = m.profit(b)
+	}
+	switch {
+	case !xok || !yok:
+		return boolCompare(yok, xok)
+	case x < y && m.asc, x > y && !m.asc:
+		return -1
+	case x == y:
+		return 0
+	}
+	return 1
+}
+
+func boolCompare(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+func (m *model) move(by int) {
+	m.cursor = min(max(m.cursor+by, 0), max(len(m.rows)-1, 0))
+	m.scroll()
+}
+
+// scroll keeps the cursor inside the rows on screen.
+func (m *model) scroll() {
+	rows := m.visibleRows()
+	m.offset = min(m.offset, m.cursor)
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+	m.offset = max(min(m.offset, len(m.rows)-rows), 0)
+}
+
+// entry is where the side that triggered got in.
+func entry(t api.CryptoModel) float64 {
+	if t.TriggeredPosition == "short" {
+		return t.SellPrice
+	}
+	return t.BuyPrice
+}
+
+// profit is the trade's P&L in percent: realized from the exit for a
+// closed trade, the live figure from the socket for an open one. ok is
+// false while neither is known.
+func (m *model) profit(t api.CryptoModel) (float64, bool) {
+	if t.Status == "closed" {
+		e := entry(t)
+		if t.ExitPrice == 0 || e == 0 {
+			return 0, false
+		}
+		change := (t.ExitPrice - e) / e * 100
+		if t.TriggeredPosition == "short" {
+			change = -change
+		}
+		return change, true
+	}
+	res, ok := m.pnl[t.Id]
+	if !ok {
+		return 0, false
+	}
+	if res.Kind == "loss" {
+		return -res.Value, true
+	}
+	return res.Value, true
+}
+
+// duration is how long the trade ran, up to now while it is open.
+func duration(t api.CryptoModel, now time.Time) time.Duration {
+	if t.TriggeredAt == 0 {
+		return 0
+	}
+	end := now
+	if t.ClosureAt > 0 {
+		end = time.UnixMilli(t.ClosureAt)
+	}
+	return max(end.Sub(time.UnixMilli(t.TriggeredAt)), 0)
+}